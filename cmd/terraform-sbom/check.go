@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+
+	"rodstewart/terraform-sbom/internal/cli"
+	"rodstewart/terraform-sbom/internal/policy"
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// runCheck implements the check subcommand. Violations are printed to standard output
+// and reported as an error so that the command exits with a non-zero status.
+func runCheck(args []string) error {
+	config, err := cli.ParseCheckFlags(args)
+	if err != nil {
+		return err
+	}
+
+	p, err := policy.Load(config.PolicyPath)
+	if err != nil {
+		return err
+	}
+
+	s, err := sbom.GenerateWithOptions(config.ConfigPath, sbom.Options{
+		Recursive:        config.Recursive,
+		ResolveInstalled: config.Resolve,
+		Transitive:       config.Transitive,
+	})
+	if err != nil {
+		return err
+	}
+	printDiagnostics(config.ConfigPath, s)
+
+	violations := policy.Check(p, s)
+	for _, violation := range violations {
+		fmt.Println(violation)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d policy violation(s) in %d module(s)", len(violations), len(s.Modules))
+	}
+
+	fmt.Printf("Checked %d module(s): no policy violations\n", len(s.Modules))
+	return nil
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/cli"
+	"rodstewart/terraform-sbom/internal/diff"
+	"rodstewart/terraform-sbom/internal/git"
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// runDiff implements the diff subcommand
+func runDiff(args []string) error {
+	config, err := cli.ParseDiffFlags(args)
+	if err != nil {
+		return err
+	}
+
+	var oldSBOM, newSBOM *sbom.SBOM
+	if config.Base != "" {
+		oldSBOM, newSBOM, err = scanRevisions(config)
+	} else {
+		oldSBOM, newSBOM, err = loadSBOMs(config)
+	}
+	if err != nil {
+		return err
+	}
+
+	var writer io.Writer = os.Stdout
+	if config.Output != "" {
+		file, err := os.Create(config.Output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		writer = file
+	}
+
+	return diff.Write(writer, diff.Compare(oldSBOM, newSBOM), config.Format)
+}
+
+// loadSBOMs reads the old and new SBOM files
+func loadSBOMs(config *cli.DiffConfig) (*sbom.SBOM, *sbom.SBOM, error) {
+	oldSBOM, err := diff.Load(config.OldPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	newSBOM, err := diff.Load(config.NewPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldSBOM, newSBOM, nil
+}
+
+// scanRevisions generates SBOMs of the configuration at the base revision and at the
+// head revision, or the working tree when no head revision is given. Paths are recorded
+// relative to the configuration so that module calls match between the two scans.
+func scanRevisions(config *cli.DiffConfig) (*sbom.SBOM, *sbom.SBOM, error) {
+	repo, err := git.Open(config.ConfigPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	absPath, err := filepath.Abs(config.ConfigPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	subdir, err := filepath.Rel(repo.Root(), absPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to locate %s in the repository: %w", config.ConfigPath, err)
+	}
+
+	options := sbom.Options{
+		Recursive:    config.Recursive,
+		Transitive:   config.Transitive,
+		Reproducible: true,
+	}
+
+	oldSBOM, err := scanRevision(repo, config.Base, subdir, options)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var newSBOM *sbom.SBOM
+	if config.Head != "" {
+		newSBOM, err = scanRevision(repo, config.Head, subdir, options)
+	} else {
+		newSBOM, err = sbom.GenerateWithOptions(config.ConfigPath, options)
+		if err == nil {
+			printDiagnostics("working tree", newSBOM)
+		}
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return oldSBOM, newSBOM, nil
+}
+
+// scanRevision extracts the Terraform files of a revision into a temporary directory
+// and generates an SBOM of subdir within it
+func scanRevision(repo *git.Repository, revision, subdir string, options sbom.Options) (*sbom.SBOM, error) {
+	tmpDir, err := os.MkdirTemp("", "terraform-sbom-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if _, err := repo.Extract(revision, tmpDir, isTerraformFile); err != nil {
+		return nil, fmt.Errorf("failed to read revision %s: %w", revision, err)
+	}
+
+	// A directory missing at this revision has no module calls
+	dir := filepath.Join(tmpDir, subdir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	s, err := sbom.GenerateWithOptions(dir, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan revision %s: %w", revision, err)
+	}
+	printDiagnostics(revision, s)
+	return s, nil
+}
+
+// isTerraformFile reports whether a repository file is read when generating an SBOM
+func isTerraformFile(name string) bool {
+	base := path.Base(name)
+	return strings.HasSuffix(base, ".tf") ||
+		strings.HasSuffix(base, ".tf.json") ||
+		base == sbom.LockFileName ||
+		strings.HasSuffix(name, filepath.ToSlash(sbom.ModuleManifestPath))
+}
+
+// printDiagnostics reports the diagnostics of a scan as warnings
+func printDiagnostics(scope string, s *sbom.SBOM) {
+	for _, diagnostic := range s.Diagnostics {
+		fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", scope, diagnostic)
+	}
+}
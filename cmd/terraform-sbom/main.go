@@ -1,18 +1,39 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"strings"
 
 	"rodstewart/terraform-sbom/internal/cli"
 	"rodstewart/terraform-sbom/internal/export"
+	"rodstewart/terraform-sbom/internal/policy"
 	"rodstewart/terraform-sbom/internal/sbom"
+	"rodstewart/terraform-sbom/internal/vuln"
 )
 
+// subcommands maps the first argument to the subcommand it runs
+var subcommands = map[string]func(args []string) error{
+	"diff":  runDiff,
+	"check": runCheck,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if subcommand, ok := subcommands[os.Args[1]]; ok {
+			if err := subcommand(os.Args[2:]); err != nil && !errors.Is(err, flag.ErrHelp) {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	config, err := cli.ParseFlags()
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -21,17 +42,110 @@ func main() {
 		fmt.Printf("Output formats: %s\n", strings.Join(config.Format, ", "))
 	}
 
-	s, err := sbom.Generate(config.ConfigPath, config.Recursive)
+	s, err := sbom.GenerateWithOptions(config.ConfigPath, sbom.Options{
+		Recursive:        config.Recursive,
+		ResolveInstalled: config.Resolve,
+		Transitive:       config.Transitive,
+		Resources:        config.Resources,
+		Strict:           config.Strict,
+		Jobs:             config.Jobs,
+		Reproducible:     config.Reproducible,
+	})
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 
+	if config.OSV != "" {
+		db, err := vuln.Load(config.OSV)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if config.Verbose {
+			fmt.Printf("Loaded %d advisories from %s\n", db.Len(), config.OSV)
+		}
+		s.Vulnerabilities = vuln.Match(db, s)
+
+		for _, path := range config.VEX {
+			doc, err := vuln.LoadVEX(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			doc.Apply(s.Vulnerabilities)
+		}
+	}
+
+	if config.PolicyPath != "" {
+		p, err := policy.Load(config.PolicyPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		s.PolicyViolations = policy.Check(p, s)
+	}
+
+	for _, diagnostic := range s.Diagnostics {
+		if diagnostic.Severity == sbom.DiagnosticError {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", diagnostic)
+		} else {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", diagnostic)
+		}
+	}
+
 	if len(s.Modules) == 0 {
 		fmt.Fprintf(os.Stderr, "Warning: No module calls found in %s\n", config.ConfigPath)
 	} else {
 		fmt.Printf("Found %d module(s)\n", len(s.Modules))
 	}
+	if config.Verbose {
+		for _, configuration := range s.Configurations {
+			fmt.Printf("  %s (%s): %d module call(s)\n", configuration.Path, configuration.Kind, len(configuration.Modules))
+		}
+	}
+	if len(s.Providers) > 0 {
+		fmt.Printf("Found %d provider(s)\n", len(s.Providers))
+	}
+	if config.Resources {
+		fmt.Printf("Found %d resource(s)\n", len(s.Resources))
+	}
+	if config.OSV != "" {
+		suppressed := 0
+		for _, finding := range s.Vulnerabilities {
+			if finding.Suppressed() {
+				suppressed++
+			}
+		}
+		fmt.Printf("Found %d vulnerabilit(ies)\n", len(s.Vulnerabilities)-suppressed)
+		if suppressed > 0 {
+			fmt.Printf("Suppressed %d vulnerabilit(ies) marked not_affected by VEX\n", suppressed)
+		}
+	}
+	for _, finding := range s.Vulnerabilities {
+		if finding.Suppressed() {
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %s %s %s is affected by %s\n", finding.ComponentType, finding.Component, finding.Version, finding.ID)
+	}
+	if config.PolicyPath != "" {
+		fmt.Printf("Found %d policy violation(s)\n", len(s.PolicyViolations))
+	}
+	for _, violation := range s.PolicyViolations {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", violation)
+	}
+	for _, issue := range s.LockIssues {
+		switch issue.Issue {
+		case sbom.LockIssueMissingFromLock:
+			fmt.Fprintf(os.Stderr, "Warning: provider %s is required but missing from %s\n", issue.Provider, issue.LockFile)
+		case sbom.LockIssueNotRequired:
+			fmt.Fprintf(os.Stderr, "Warning: provider %s is locked in %s but not required\n", issue.Provider, issue.LockFile)
+		}
+	}
+	for _, conflict := range s.CoreConflicts {
+		fmt.Fprintf(os.Stderr, "Warning: required_version %q in %s cannot be satisfied together with %q in %s\n",
+			conflict.Constraint, conflict.Directory, conflict.ConflictingConstraint, conflict.ConflictingDirectory)
+	}
 
 	// Export SBOM in all requested formats
 	for _, formatType := range config.Format {
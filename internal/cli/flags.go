@@ -9,20 +9,38 @@ import (
 
 // Config holds the parsed command line configuration
 type Config struct {
-	Format     []string
-	Output     string
-	Verbose    bool
-	Recursive  bool
-	ConfigPath string
+	Format       []string
+	Output       string
+	Verbose      bool
+	Recursive    bool
+	Resolve      bool
+	Transitive   bool
+	Resources    bool
+	Strict       bool
+	Jobs         int
+	Reproducible bool
+	OSV          string
+	VEX          []string
+	PolicyPath   string
+	ConfigPath   string
 }
 
 // ParseFlags parses command line flags and returns the configuration
 func ParseFlags() (*Config, error) {
 	var (
-		format    = flag.String("f", "json", "Output format(s) - comma-separated (json, xml, csv, tsv)")
-		output    = flag.String("o", "", "Output file path base (extensions added automatically)")
-		verbose   = flag.Bool("v", false, "Verbose output")
-		recursive = flag.Bool("r", false, "Recursively scan for Terraform modules")
+		format       = flag.String("f", "json", "Output format(s) - comma-separated (json, xml, csv, tsv, cyclonedx-json, cyclonedx-xml, spdx-json, spdx, spdx3, dot, mermaid, openvex, sarif)")
+		output       = flag.String("o", "", "Output file path base (extensions added automatically)")
+		verbose      = flag.Bool("v", false, "Verbose output")
+		recursive    = flag.Bool("r", false, "Recursively scan for Terraform modules")
+		resolve      = flag.Bool("resolve", false, "Resolve installed module versions from .terraform/modules/modules.json (requires terraform init)")
+		transitive   = flag.Bool("transitive", false, "Follow local and installed module calls to record nested module calls")
+		resources    = flag.Bool("resources", false, "Record managed resources and data sources, with counts per provider")
+		jobs         = flag.Int("j", 0, "Number of directories to parse concurrently (0 uses one per CPU)")
+		reproducible = flag.Bool("reproducible", false, "Record paths relative to the terraform directory and a fixed timestamp (SOURCE_DATE_EPOCH or the Unix epoch)")
+		strict       = flag.Bool("strict", false, "Fail on the first directory that cannot be loaded instead of recording a diagnostic and continuing")
+		osv          = flag.String("osv", "", "Match modules and providers against OSV advisories in a directory or zip archive of JSON files")
+		policy       = flag.String("policy", "", "Record violations of a policy file (HCL or JSON) in the SBOM, as the check subcommand reports them")
+		vex          = flag.String("vex", "", "OpenVEX document(s) - comma-separated - whose not_affected statements suppress matching vulnerabilities (requires -osv)")
 	)
 	flag.Parse()
 
@@ -39,18 +57,39 @@ func ParseFlags() (*Config, error) {
 		formats[i] = strings.TrimSpace(fmt)
 	}
 
+	var vexPaths []string
+	if *vex != "" {
+		if *osv == "" {
+			return nil, fmt.Errorf("-vex requires -osv")
+		}
+		for _, path := range strings.Split(*vex, ",") {
+			vexPaths = append(vexPaths, strings.TrimSpace(path))
+		}
+	}
+
 	return &Config{
-		Format:     formats,
-		Output:     *output,
-		Verbose:    *verbose,
-		Recursive:  *recursive,
-		ConfigPath: configPath,
+		Format:       formats,
+		Output:       *output,
+		Verbose:      *verbose,
+		Recursive:    *recursive,
+		Resolve:      *resolve,
+		Transitive:   *transitive,
+		Resources:    *resources,
+		Strict:       *strict,
+		Jobs:         *jobs,
+		Reproducible: *reproducible,
+		OSV:          *osv,
+		VEX:          vexPaths,
+		PolicyPath:   *policy,
+		ConfigPath:   configPath,
 	}, nil
 }
 
 // printUsage prints the usage information
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage: %s [options] <terraform-directory>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s diff [options] <old.json> <new.json>\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s check -policy <policy.hcl> [options] <terraform-directory>\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, "\nOptions:\n")
 	flag.PrintDefaults()
 	fmt.Fprintf(os.Stderr, "\nArguments:\n")
@@ -58,4 +97,127 @@ func printUsage() {
 	fmt.Fprintf(os.Stderr, "\nExamples:\n")
 	fmt.Fprintf(os.Stderr, "  %s -f json -o sbom.json ./terraform\n", os.Args[0])
 	fmt.Fprintf(os.Stderr, "  %s -r -f json -o sbom ./project    # Recursively scan all modules\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s -resolve -f csv ./terraform     # Report installed module versions\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s -osv ./osv-mirror ./terraform     # Flag known vulnerabilities\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s -osv ./osv-mirror -vex triage.json -f json,openvex ./terraform\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s -policy policy.hcl -osv ./osv-mirror -f sarif ./terraform  # Code scanning results\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s diff -f markdown old.json new.json  # Summarize module changes\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s diff -base main -r ./terraform    # Compare main with the working tree\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "  %s check -policy policy.hcl -r .     # Fail on unpinned or disallowed sources\n", os.Args[0])
+}
+
+// DiffConfig holds the parsed configuration of the diff subcommand. When Base is set,
+// the configuration in ConfigPath is scanned at the Base and Head revisions of its git
+// repository instead of reading OldPath and NewPath.
+type DiffConfig struct {
+	Format     string
+	Output     string
+	OldPath    string
+	NewPath    string
+	Base       string
+	Head       string
+	Recursive  bool
+	Transitive bool
+	ConfigPath string
+}
+
+// ParseDiffFlags parses the arguments of the diff subcommand
+func ParseDiffFlags(args []string) (*DiffConfig, error) {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		format     = flags.String("f", "text", "Output format (text, json, markdown)")
+		output     = flags.String("o", "", "Output file path (default standard output)")
+		base       = flags.String("base", "", "Git revision to compare from, scanning the repository instead of reading SBOM files")
+		head       = flags.String("head", "", "Git revision to compare to with -base (default the working tree)")
+		recursive  = flags.Bool("r", false, "Recursively scan for Terraform modules with -base")
+		transitive = flags.Bool("transitive", false, "Follow local module calls to record nested module calls with -base")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s diff [options] <old.json> <new.json>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s diff -base <revision> [-head <revision>] [options] [terraform-directory]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nCompares two SBOMs written in json format, or the configuration at two git revisions, and reports module calls added, removed, version changed and source changed.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	config := &DiffConfig{
+		Format:     *format,
+		Output:     *output,
+		Base:       *base,
+		Head:       *head,
+		Recursive:  *recursive,
+		Transitive: *transitive,
+	}
+
+	if *base != "" {
+		if flags.NArg() > 1 {
+			flags.Usage()
+			return nil, fmt.Errorf("diff -base accepts at most one terraform directory")
+		}
+		config.ConfigPath = "."
+		if flags.NArg() == 1 {
+			config.ConfigPath = flags.Arg(0)
+		}
+		return config, nil
+	}
+
+	if *head != "" {
+		return nil, fmt.Errorf("diff -head requires -base")
+	}
+	if flags.NArg() != 2 {
+		flags.Usage()
+		return nil, fmt.Errorf("diff requires the old and new SBOM paths")
+	}
+	config.OldPath = flags.Arg(0)
+	config.NewPath = flags.Arg(1)
+	return config, nil
+}
+
+// CheckConfig holds the parsed configuration of the check subcommand
+type CheckConfig struct {
+	PolicyPath string
+	Recursive  bool
+	Resolve    bool
+	Transitive bool
+	ConfigPath string
+}
+
+// ParseCheckFlags parses the arguments of the check subcommand
+func ParseCheckFlags(args []string) (*CheckConfig, error) {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	var (
+		policy     = flags.String("policy", "", "Policy file (HCL or JSON)")
+		recursive  = flags.Bool("r", false, "Recursively scan for Terraform modules")
+		resolve    = flags.Bool("resolve", false, "Resolve installed module versions from .terraform/modules/modules.json (requires terraform init)")
+		transitive = flags.Bool("transitive", false, "Follow local and installed module calls to check nested module calls")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s check -policy <policy.hcl> [options] <terraform-directory>\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "\nChecks module calls against a policy and exits with a non-zero status when any violate it.\n")
+		fmt.Fprintf(os.Stderr, "\nOptions:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+
+	if *policy == "" {
+		flags.Usage()
+		return nil, fmt.Errorf("check requires a -policy file")
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return nil, fmt.Errorf("check requires a terraform-directory argument")
+	}
+
+	return &CheckConfig{
+		PolicyPath: *policy,
+		Recursive:  *recursive,
+		Resolve:    *resolve,
+		Transitive: *transitive,
+		ConfigPath: flags.Arg(0),
+	}, nil
 }
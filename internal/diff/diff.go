@@ -0,0 +1,179 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// ModuleChange is a module call present in both SBOMs whose version or source differs
+type ModuleChange struct {
+	Name       string `json:"name"`
+	Address    string `json:"address"`
+	Root       string `json:"root,omitempty"`
+	OldSource  string `json:"old_source"`
+	NewSource  string `json:"new_source"`
+	OldVersion string `json:"old_version"`
+	NewVersion string `json:"new_version"`
+}
+
+// Result lists the module calls added, removed and changed between two SBOMs. A
+// module whose version and source both changed appears in both change lists.
+type Result struct {
+	Added          []sbom.ModuleInfo `json:"added"`
+	Removed        []sbom.ModuleInfo `json:"removed"`
+	VersionChanged []ModuleChange    `json:"version_changed"`
+	SourceChanged  []ModuleChange    `json:"source_changed"`
+}
+
+// Empty reports whether the SBOMs have the same module calls
+func (r *Result) Empty() bool {
+	return len(r.Added) == 0 && len(r.Removed) == 0 && len(r.VersionChanged) == 0 && len(r.SourceChanged) == 0
+}
+
+// Load reads an SBOM written by export.JSON
+func Load(path string) (*sbom.SBOM, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SBOM %s: %w", path, err)
+	}
+
+	var s sbom.SBOM
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// moduleKey identifies a module call by its root configuration, relative to the
+// scanned directory, and its address, so that scans of different checkouts compare
+type moduleKey struct {
+	root    string
+	address string
+}
+
+// keyOf returns the key of a module, falling back to the calling file's directory and
+// the module name for SBOMs written before roots and addresses were recorded
+func keyOf(module sbom.ModuleInfo) moduleKey {
+	key := moduleKey{root: module.Root, address: module.Path}
+	if key.root == "" {
+		key.root = filepath.Dir(module.Filename)
+	}
+	if key.address == "" {
+		key.address = "module." + module.Name
+	}
+	return key
+}
+
+// Compare reports the module calls added, removed and changed from old to new. Each
+// list is sorted by root and address.
+func Compare(old, new *sbom.SBOM) *Result {
+	oldModules := indexModules(relativeRoots(old.Modules))
+	newModules := indexModules(relativeRoots(new.Modules))
+
+	result := &Result{
+		Added:          []sbom.ModuleInfo{},
+		Removed:        []sbom.ModuleInfo{},
+		VersionChanged: []ModuleChange{},
+		SourceChanged:  []ModuleChange{},
+	}
+	for _, key := range sortedKeys(newModules) {
+		module := newModules[key]
+		previous, ok := oldModules[key]
+		if !ok {
+			result.Added = append(result.Added, module)
+			continue
+		}
+
+		change := ModuleChange{
+			Name:       module.Name,
+			Address:    key.address,
+			Root:       key.root,
+			OldSource:  previous.Source,
+			NewSource:  module.Source,
+			OldVersion: previous.Version,
+			NewVersion: module.Version,
+		}
+		if previous.Version != module.Version {
+			result.VersionChanged = append(result.VersionChanged, change)
+		}
+		if previous.Source != module.Source {
+			result.SourceChanged = append(result.SourceChanged, change)
+		}
+	}
+	for _, key := range sortedKeys(oldModules) {
+		if _, ok := newModules[key]; !ok {
+			result.Removed = append(result.Removed, oldModules[key])
+		}
+	}
+
+	return result
+}
+
+// relativeRoots returns copies of modules whose absolute roots are rewritten relative
+// to the scanned directory, using forward slashes. The scanned directory is recovered
+// from a module whose file is recorded both absolutely and relative to it; SBOMs
+// written before relative file paths were recorded are returned unchanged.
+func relativeRoots(modules []sbom.ModuleInfo) []sbom.ModuleInfo {
+	base := scanBase(modules)
+	if base == "" {
+		return modules
+	}
+
+	relative := make([]sbom.ModuleInfo, len(modules))
+	for i, module := range modules {
+		if filepath.IsAbs(module.Root) {
+			if rel, err := filepath.Rel(base, module.Root); err == nil {
+				module.Root = filepath.ToSlash(rel)
+			}
+		}
+		relative[i] = module
+	}
+	return relative
+}
+
+// scanBase returns the directory an SBOM was generated from, or "" if it cannot be told
+func scanBase(modules []sbom.ModuleInfo) string {
+	for _, module := range modules {
+		if module.FilePath == "" || !filepath.IsAbs(module.Filename) {
+			continue
+		}
+		suffix := string(filepath.Separator) + filepath.FromSlash(module.FilePath)
+		if strings.HasSuffix(module.Filename, suffix) {
+			return strings.TrimSuffix(module.Filename, suffix)
+		}
+	}
+	return ""
+}
+
+// indexModules keys modules by root and address. Duplicate keys keep the first module.
+func indexModules(modules []sbom.ModuleInfo) map[moduleKey]sbom.ModuleInfo {
+	index := make(map[moduleKey]sbom.ModuleInfo, len(modules))
+	for _, module := range modules {
+		key := keyOf(module)
+		if _, exists := index[key]; !exists {
+			index[key] = module
+		}
+	}
+	return index
+}
+
+// sortedKeys returns the keys of an index ordered by root and address
+func sortedKeys(index map[moduleKey]sbom.ModuleInfo) []moduleKey {
+	keys := make([]moduleKey, 0, len(index))
+	for key := range index {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].root != keys[j].root {
+			return keys[i].root < keys[j].root
+		}
+		return keys[i].address < keys[j].address
+	})
+	return keys
+}
@@ -0,0 +1,182 @@
+package diff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// oldSBOM and newSBOM upgrade vpc, add eks, remove legacy and move app to a new repository
+var (
+	oldSBOM = &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Filename: "main.tf", Root: ".", Path: "module.vpc", Depth: 1},
+			{Name: "legacy", Source: "./modules/legacy", Filename: "main.tf", Root: ".", Path: "module.legacy", Depth: 1},
+			{Name: "app", Source: "git::https://example.com/app.git?ref=v1.0.0", Filename: "main.tf", Root: ".", Path: "module.app", Depth: 1},
+			{Name: "sg", Source: "terraform-aws-modules/security-group/aws", Version: "4.17.2", Filename: "modules/net/main.tf", Root: ".", Path: "module.net.module.sg", Parent: "module.net", Depth: 2},
+		},
+	}
+	newSBOM = &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.8.1", Filename: "main.tf", Root: ".", Path: "module.vpc", Depth: 1},
+			{Name: "eks", Source: "terraform-aws-modules/eks/aws", Version: "19.0.0", Filename: "main.tf", Root: ".", Path: "module.eks", Depth: 1},
+			{Name: "app", Source: "git::https://example.com/platform/app.git?ref=v1.0.0", Filename: "main.tf", Root: ".", Path: "module.app", Depth: 1},
+			{Name: "sg", Source: "terraform-aws-modules/security-group/aws", Version: "4.17.2", Filename: "modules/net/main.tf", Root: ".", Path: "module.net.module.sg", Parent: "module.net", Depth: 2},
+		},
+	}
+)
+
+func TestCompare(t *testing.T) {
+	result := Compare(oldSBOM, newSBOM)
+
+	if len(result.Added) != 1 || result.Added[0].Name != "eks" {
+		t.Errorf("Added = %+v, want eks", result.Added)
+	}
+	if len(result.Removed) != 1 || result.Removed[0].Name != "legacy" {
+		t.Errorf("Removed = %+v, want legacy", result.Removed)
+	}
+
+	expectedVersion := []ModuleChange{
+		{Name: "vpc", Address: "module.vpc", Root: ".", OldSource: "terraform-aws-modules/vpc/aws", NewSource: "terraform-aws-modules/vpc/aws", OldVersion: "5.1.0", NewVersion: "5.8.1"},
+	}
+	if !reflect.DeepEqual(result.VersionChanged, expectedVersion) {
+		t.Errorf("VersionChanged = %+v, want %+v", result.VersionChanged, expectedVersion)
+	}
+
+	expectedSource := []ModuleChange{
+		{Name: "app", Address: "module.app", Root: ".", OldSource: "git::https://example.com/app.git?ref=v1.0.0", NewSource: "git::https://example.com/platform/app.git?ref=v1.0.0"},
+	}
+	if !reflect.DeepEqual(result.SourceChanged, expectedSource) {
+		t.Errorf("SourceChanged = %+v, want %+v", result.SourceChanged, expectedSource)
+	}
+
+	if result.Empty() {
+		t.Error("Empty() = true, want false")
+	}
+	if !Compare(oldSBOM, oldSBOM).Empty() {
+		t.Error("Compare(old, old).Empty() = false, want true")
+	}
+}
+
+func TestCompareMatchesByRoot(t *testing.T) {
+	old := &sbom.SBOM{Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Root: "dev", Path: "module.vpc"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Root: "prod", Path: "module.vpc"},
+	}}
+	updated := &sbom.SBOM{Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Root: "dev", Path: "module.vpc"},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Root: "prod", Path: "module.vpc"},
+	}}
+
+	result := Compare(old, updated)
+	if len(result.VersionChanged) != 1 || result.VersionChanged[0].Root != "dev" {
+		t.Errorf("VersionChanged = %+v, want only the dev configuration", result.VersionChanged)
+	}
+}
+
+func TestCompareLegacySBOM(t *testing.T) {
+	// SBOMs without roots or addresses are matched by file directory and name
+	old := &sbom.SBOM{Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.0.0", Filename: "/project/main.tf"},
+	}}
+	updated := &sbom.SBOM{Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0", Filename: "/project/network.tf"},
+	}}
+
+	result := Compare(old, updated)
+	if len(result.VersionChanged) != 1 || len(result.Added) != 0 || len(result.Removed) != 0 {
+		t.Errorf("Compare() = %+v, want a single version change", result)
+	}
+}
+
+func TestCompareRelocatedScans(t *testing.T) {
+	config := map[string]string{
+		"main.tf": `
+module "network" {
+  source = "./modules/network"
+}
+`,
+		"modules/network/main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+`,
+	}
+
+	// The same configuration checked out in two places and scanned without -reproducible
+	var scans []*sbom.SBOM
+	for range 2 {
+		dir := t.TempDir()
+		for name, content := range config {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("failed to create directory for %s: %v", name, err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		s, err := sbom.GenerateWithOptions(dir, sbom.Options{Recursive: true, Transitive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+		scans = append(scans, s)
+	}
+	if scans[0].Modules[0].Root == scans[1].Modules[0].Root {
+		t.Fatalf("Root = %q in both scans, want different checkouts", scans[0].Modules[0].Root)
+	}
+
+	if result := Compare(scans[0], scans[1]); !result.Empty() {
+		t.Errorf("Compare() = %+v, want no changes between relocated scans", result)
+	}
+
+	scans[1].Modules[0].Version = "5.8.1"
+	result := Compare(scans[0], scans[1])
+	if len(result.VersionChanged) != 1 || result.VersionChanged[0].Root != "." || len(result.Added) != 0 || len(result.Removed) != 0 {
+		t.Errorf("Compare() = %+v, want a single version change in root .", result)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	t.Run("valid SBOM", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "sbom.json")
+		content := `{"version":"1.0","generated":"2024-01-02T03:04:05Z","tool":"terraform-sbom","modules":[{"name":"vpc","source":"terraform-aws-modules/vpc/aws","version":"5.1.0","location":"","filename":"main.tf"}]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write SBOM: %v", err)
+		}
+
+		s, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() = %v, want nil", err)
+		}
+		if len(s.Modules) != 1 || s.Modules[0].Version != "5.1.0" {
+			t.Errorf("Modules = %+v, want vpc 5.1.0", s.Modules)
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "invalid.json")
+		if err := os.WriteFile(path, []byte("<SBOM/>"), 0644); err != nil {
+			t.Fatalf("failed to write SBOM: %v", err)
+		}
+
+		_, err := Load(path)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse SBOM") {
+			t.Errorf("Load() = %v, want 'failed to parse SBOM'", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := Load(filepath.Join(tmpDir, "missing.json"))
+		if err == nil || !strings.Contains(err.Error(), "failed to read SBOM") {
+			t.Errorf("Load() = %v, want 'failed to read SBOM'", err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package diff
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// Output formats supported by Write
+const (
+	FormatText     = "text"
+	FormatJSON     = "json"
+	FormatMarkdown = "markdown"
+)
+
+// Write writes a diff result in the given format
+func Write(w io.Writer, result *Result, format string) error {
+	switch format {
+	case FormatText:
+		return Text(w, result)
+	case FormatJSON:
+		return JSON(w, result)
+	case FormatMarkdown:
+		return Markdown(w, result)
+	default:
+		return fmt.Errorf("unsupported diff format: %s (supported: text, json, markdown)", format)
+	}
+}
+
+// Text writes a diff result as one line per change for terminals and logs
+func Text(writer io.Writer, result *Result) error {
+	w := bufio.NewWriter(writer)
+	roots := multipleRoots(result)
+
+	if result.Empty() {
+		fmt.Fprintln(w, "No module changes")
+	} else {
+		fmt.Fprintf(w, "%d added, %d removed, %d version changed, %d source changed\n",
+			len(result.Added), len(result.Removed), len(result.VersionChanged), len(result.SourceChanged))
+	}
+	for _, module := range result.Added {
+		fmt.Fprintf(w, "+ %s %s\n", moduleName(module, roots), strings.TrimSpace(module.Source+" "+module.Version))
+	}
+	for _, module := range result.Removed {
+		fmt.Fprintf(w, "- %s %s\n", moduleName(module, roots), strings.TrimSpace(module.Source+" "+module.Version))
+	}
+	for _, change := range result.VersionChanged {
+		fmt.Fprintf(w, "~ %s %s -> %s\n", changeName(change, roots), versionText(change.OldVersion), versionText(change.NewVersion))
+	}
+	for _, change := range result.SourceChanged {
+		fmt.Fprintf(w, "~ %s source %s -> %s\n", changeName(change, roots), change.OldSource, change.NewSource)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	return nil
+}
+
+// JSON writes a diff result as an indented JSON document
+func JSON(writer io.Writer, result *Result) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(result); err != nil {
+		return fmt.Errorf("failed to encode diff to JSON: %w", err)
+	}
+	return nil
+}
+
+// Markdown writes a diff result as a bulleted summary suitable for a pull request comment
+func Markdown(writer io.Writer, result *Result) error {
+	w := bufio.NewWriter(writer)
+	roots := multipleRoots(result)
+
+	fmt.Fprint(w, "### Terraform module changes\n\n")
+	if result.Empty() {
+		fmt.Fprintln(w, "No module changes.")
+	}
+	for _, module := range result.Added {
+		fmt.Fprintf(w, "- Adds %s (%s)\n", code(moduleName(module, roots)), moduleDescription(module))
+	}
+	for _, module := range result.Removed {
+		fmt.Fprintf(w, "- Removes %s (%s)\n", code(moduleName(module, roots)), moduleDescription(module))
+	}
+	for _, change := range result.VersionChanged {
+		fmt.Fprintf(w, "- %s %s from %s to %s\n", versionVerb(change.OldVersion, change.NewVersion),
+			code(changeName(change, roots)), code(versionText(change.OldVersion)), code(versionText(change.NewVersion)))
+	}
+	for _, change := range result.SourceChanged {
+		fmt.Fprintf(w, "- Changes the source of %s from %s to %s\n", code(changeName(change, roots)), code(change.OldSource), code(change.NewSource))
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write diff: %w", err)
+	}
+	return nil
+}
+
+// multipleRoots reports whether the changes span more than one root configuration, in
+// which case module names are qualified with their root
+func multipleRoots(result *Result) bool {
+	roots := make(map[string]bool)
+	for _, module := range append(append([]sbom.ModuleInfo{}, result.Added...), result.Removed...) {
+		roots[keyOf(module).root] = true
+	}
+	for _, change := range append(append([]ModuleChange{}, result.VersionChanged...), result.SourceChanged...) {
+		roots[change.Root] = true
+	}
+	return len(roots) > 1
+}
+
+// moduleName names a module by its call name, or by its address when it is nested
+func moduleName(module sbom.ModuleInfo, withRoot bool) string {
+	key := keyOf(module)
+	name := module.Name
+	if module.Parent != "" {
+		name = key.address
+	}
+	if withRoot {
+		name += " in " + key.root
+	}
+	return name
+}
+
+// changeName names a changed module in the same way as moduleName
+func changeName(change ModuleChange, withRoot bool) string {
+	name := change.Name
+	if strings.Count(change.Address, "module.") > 1 {
+		name = change.Address
+	}
+	if withRoot && change.Root != "" {
+		name += " in " + change.Root
+	}
+	return name
+}
+
+// moduleDescription formats a module's source and version
+func moduleDescription(module sbom.ModuleInfo) string {
+	if module.Version == "" {
+		return code(module.Source)
+	}
+	return code(module.Source) + " " + code(module.Version)
+}
+
+// versionVerb describes the direction of a version change
+func versionVerb(oldVersion, newVersion string) string {
+	from, errFrom := sbom.ParseVersion(oldVersion)
+	to, errTo := sbom.ParseVersion(newVersion)
+	if errFrom != nil || errTo != nil {
+		return "Changes the version of"
+	}
+	if to.Compare(from) < 0 {
+		return "Downgrades"
+	}
+	return "Upgrades"
+}
+
+// versionText returns a version for display, naming the absence of one
+func versionText(version string) string {
+	if version == "" {
+		return "unversioned"
+	}
+	return version
+}
+
+// code formats a value as inline Markdown code
+func code(value string) string {
+	return "`" + strings.ReplaceAll(value, "`", "'") + "`"
+}
@@ -0,0 +1,121 @@
+package diff
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestText(t *testing.T) {
+	var buffer strings.Builder
+	if err := Write(&buffer, Compare(oldSBOM, newSBOM), FormatText); err != nil {
+		t.Fatalf("Write() = %v, want nil", err)
+	}
+
+	expected := `1 added, 1 removed, 1 version changed, 1 source changed
++ eks terraform-aws-modules/eks/aws 19.0.0
+- legacy ./modules/legacy
+~ vpc 5.1.0 -> 5.8.1
+~ app source git::https://example.com/app.git?ref=v1.0.0 -> git::https://example.com/platform/app.git?ref=v1.0.0
+`
+	if buffer.String() != expected {
+		t.Errorf("Text output = %q, want %q", buffer.String(), expected)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var buffer strings.Builder
+	if err := Write(&buffer, Compare(oldSBOM, newSBOM), FormatMarkdown); err != nil {
+		t.Fatalf("Write() = %v, want nil", err)
+	}
+
+	expected := "### Terraform module changes\n\n" +
+		"- Adds `eks` (`terraform-aws-modules/eks/aws` `19.0.0`)\n" +
+		"- Removes `legacy` (`./modules/legacy`)\n" +
+		"- Upgrades `vpc` from `5.1.0` to `5.8.1`\n" +
+		"- Changes the source of `app` from `git::https://example.com/app.git?ref=v1.0.0` to `git::https://example.com/platform/app.git?ref=v1.0.0`\n"
+	if buffer.String() != expected {
+		t.Errorf("Markdown output = %q, want %q", buffer.String(), expected)
+	}
+
+	t.Run("version direction", func(t *testing.T) {
+		tests := []struct {
+			old      string
+			new      string
+			expected string
+		}{
+			{"5.1.0", "5.8.1", "Upgrades"},
+			{"5.8.1", "5.1.0", "Downgrades"},
+			{"~> 5.0", "5.8.1", "Changes the version of"},
+		}
+		for _, test := range tests {
+			if result := versionVerb(test.old, test.new); result != test.expected {
+				t.Errorf("versionVerb(%q, %q) = %q, want %q", test.old, test.new, result, test.expected)
+			}
+		}
+	})
+
+	t.Run("nested modules and multiple roots", func(t *testing.T) {
+		result := Compare(&sbom.SBOM{}, &sbom.SBOM{Modules: []sbom.ModuleInfo{
+			{Name: "sg", Source: "./sg", Root: "dev", Path: "module.net.module.sg", Parent: "module.net"},
+			{Name: "vpc", Source: "./vpc", Root: "prod", Path: "module.vpc"},
+		}})
+
+		var buffer strings.Builder
+		if err := Markdown(&buffer, result); err != nil {
+			t.Fatalf("Markdown() = %v, want nil", err)
+		}
+		for _, want := range []string{"- Adds `module.net.module.sg in dev`", "- Adds `vpc in prod`"} {
+			if !strings.Contains(buffer.String(), want) {
+				t.Errorf("Markdown output missing %q:\n%s", want, buffer.String())
+			}
+		}
+	})
+
+	t.Run("no changes", func(t *testing.T) {
+		var buffer strings.Builder
+		if err := Markdown(&buffer, Compare(oldSBOM, oldSBOM)); err != nil {
+			t.Fatalf("Markdown() = %v, want nil", err)
+		}
+		if !strings.Contains(buffer.String(), "No module changes.") {
+			t.Errorf("Markdown output = %q, want 'No module changes.'", buffer.String())
+		}
+	})
+}
+
+func TestJSON(t *testing.T) {
+	var buffer strings.Builder
+	if err := Write(&buffer, Compare(oldSBOM, newSBOM), FormatJSON); err != nil {
+		t.Fatalf("Write() = %v, want nil", err)
+	}
+
+	var result Result
+	if err := json.Unmarshal([]byte(buffer.String()), &result); err != nil {
+		t.Fatalf("json.Unmarshal() = %v, want nil", err)
+	}
+	if len(result.Added) != 1 || len(result.Removed) != 1 || len(result.VersionChanged) != 1 || len(result.SourceChanged) != 1 {
+		t.Errorf("Result = %+v, want one change of each kind", result)
+	}
+
+	// Empty lists are written as [] rather than null
+	buffer.Reset()
+	if err := JSON(&buffer, Compare(oldSBOM, oldSBOM)); err != nil {
+		t.Fatalf("JSON() = %v, want nil", err)
+	}
+	if !strings.Contains(buffer.String(), `"added": []`) {
+		t.Errorf("JSON output = %s, want empty lists", buffer.String())
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	err := Write(&strings.Builder{}, &Result{}, "yaml")
+	if err == nil {
+		t.Fatal("Write() = nil, want error for unsupported format")
+	}
+	expected := "unsupported diff format: yaml (supported: text, json, markdown)"
+	if err.Error() != expected {
+		t.Errorf("error message = %q, want %q", err.Error(), expected)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/csv"
 	"fmt"
 	"io"
+	"strconv"
 
 	"rodstewart/terraform-sbom/internal/sbom"
 )
@@ -14,14 +15,66 @@ func exportDelimited(s *sbom.SBOM, writer io.Writer, separator rune, formatName
 	csvWriter.Comma = separator
 
 	// Write header row
-	headers := []string{"Name", "Source", "Version", "Location", "Filename"}
+	headers := []string{
+		"Name", "Source", "Version", "Location", "Filename", "PURL",
+		"SourceKind", "SourceHost", "SourceNamespace", "SourceName", "SourceProvider",
+		"SourceSubdirectory", "SourceRef", "SourceArchiveFormat", "SourceQuery", "Type",
+		"VersionConstraint", "InstalledDir", "Root", "Path", "Depth",
+		"Line", "Column", "FilePath",
+	}
 	if err := csvWriter.Write(headers); err != nil {
 		return fmt.Errorf("failed to write %s headers: %w", formatName, err)
 	}
 
 	// Write data rows
 	for _, module := range s.Modules {
-		record := []string{module.Name, module.Source, module.Version, module.Location, module.Filename}
+		source := moduleSource(module)
+		record := []string{
+			module.Name, module.Source, module.Version, module.Location, module.Filename, module.PURL,
+			string(source.Kind), source.Host, source.Namespace, source.Name, source.Provider,
+			source.Subdirectory, source.Ref, source.ArchiveFormat, source.QueryString(), "module",
+			module.VersionConstraint, module.InstalledDir, module.Root, module.Path, number(module.Depth),
+			number(module.StartLine()), number(module.Column), module.FilePath,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", formatName, err)
+		}
+	}
+
+	// Providers share the module columns; their source is always a registry address
+	for _, provider := range s.Providers {
+		host, namespace, providerType := provider.SourceParts()
+
+		// As with resolved modules, the constraint column is only filled once the version is exact
+		constraint := ""
+		if provider.Version != "" {
+			constraint = provider.Constraint()
+		}
+		record := []string{
+			provider.Name, provider.Source, provider.EffectiveVersion(), provider.Location, provider.Filename, "",
+			string(sbom.SourceRegistry), host, namespace, providerType, "",
+			"", "", "", "", "provider",
+			constraint, "", "", "", "",
+			number(provider.StartLine()), number(provider.Column), provider.FilePath,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write %s record: %w", formatName, err)
+		}
+	}
+
+	// Resources are listed by address with the provider they map to as their source
+	for _, resource := range s.Resources {
+		rowType := "resource"
+		if resource.Mode == sbom.ResourceModeData {
+			rowType = "data"
+		}
+		record := []string{
+			resource.Address, resource.ProviderSource, "", resource.Location, resource.Filename, "",
+			"", "", "", "", "",
+			"", "", "", "", rowType,
+			"", "", "", "", "",
+			"", "", "",
+		}
 		if err := csvWriter.Write(record); err != nil {
 			return fmt.Errorf("failed to write %s record: %w", formatName, err)
 		}
@@ -36,6 +89,14 @@ func exportDelimited(s *sbom.SBOM, writer io.Writer, separator rune, formatName
 	return nil
 }
 
+// number renders a module depth, line or column, leaving the column empty when it is unknown
+func number(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
 // CSV exports SBOM as CSV to the provided writer
 func CSV(s *sbom.SBOM, writer io.Writer) error {
 	return exportDelimited(s, writer, ',', "CSV")
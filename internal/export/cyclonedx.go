@@ -0,0 +1,293 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// cycloneDXSpecVersion is the CycloneDX specification version emitted by the CycloneDX exporters
+const cycloneDXSpecVersion = "1.6"
+
+// cycloneDXNamespace is the XML namespace of the CycloneDX schema version above
+const cycloneDXNamespace = "http://cyclonedx.org/schema/bom/" + cycloneDXSpecVersion
+
+// cdxBOM is the root of a CycloneDX document. The same model is used for the JSON
+// and XML serializations; field order follows the sequence required by the XSD.
+type cdxBOM struct {
+	XMLName      xml.Name        `json:"-" xml:"http://cyclonedx.org/schema/bom/1.6 bom"`
+	BOMFormat    string          `json:"bomFormat" xml:"-"`
+	SpecVersion  string          `json:"specVersion" xml:"-"`
+	SerialNumber string          `json:"serialNumber" xml:"serialNumber,attr"`
+	Version      int             `json:"version" xml:"version,attr"`
+	Metadata     cdxMetadata     `json:"metadata" xml:"metadata"`
+	Components   []cdxComponent  `json:"components" xml:"components>component"`
+	Dependencies []cdxDependency `json:"dependencies,omitempty" xml:"dependencies>dependency,omitempty"`
+}
+
+// cdxDependency lists the components a component directly depends on. JSON uses a
+// flat list of refs while XML nests a dependency element per ref.
+type cdxDependency struct {
+	Ref       string             `json:"ref" xml:"ref,attr"`
+	DependsOn []string           `json:"dependsOn,omitempty" xml:"-"`
+	Children  []cdxDependencyRef `json:"-" xml:"dependency"`
+}
+
+// cdxDependencyRef is a nested dependency reference in the XML form
+type cdxDependencyRef struct {
+	Ref string `xml:"ref,attr"`
+}
+
+// cdxMetadata describes the BOM itself and the tool that produced it
+type cdxMetadata struct {
+	Timestamp string   `json:"timestamp,omitempty" xml:"timestamp,omitempty"`
+	Tools     cdxTools `json:"tools" xml:"tools"`
+}
+
+// cdxTools lists the tools used to create the BOM
+type cdxTools struct {
+	Components []cdxComponent `json:"components" xml:"components>component"`
+}
+
+// cdxComponent represents a single CycloneDX component
+type cdxComponent struct {
+	Type       string        `json:"type" xml:"type,attr"`
+	BOMRef     string        `json:"bom-ref,omitempty" xml:"bom-ref,attr,omitempty"`
+	Name       string        `json:"name" xml:"name"`
+	Version    string        `json:"version,omitempty" xml:"version,omitempty"`
+	PURL       string        `json:"purl,omitempty" xml:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty" xml:"properties>property,omitempty"`
+	Evidence   *cdxEvidence  `json:"evidence,omitempty" xml:"evidence,omitempty"`
+}
+
+// cdxProperty is a name/value pair attached to a component
+type cdxProperty struct {
+	Name  string `json:"name" xml:"name,attr"`
+	Value string `json:"value" xml:",chardata"`
+}
+
+// cdxEvidence records where a component was observed
+type cdxEvidence struct {
+	Occurrences []cdxOccurrence `json:"occurrences" xml:"occurrences>occurrence"`
+}
+
+// cdxOccurrence is a single location a component was observed at
+type cdxOccurrence struct {
+	Location string `json:"location" xml:"location"`
+	Line     int    `json:"line,omitempty" xml:"line,omitempty"`
+}
+
+// CycloneDXJSON exports SBOM as a CycloneDX JSON document to the provided writer
+func CycloneDXJSON(s *sbom.SBOM, writer io.Writer) error {
+	bom, err := newCycloneDXBOM(s)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+
+	if err := encoder.Encode(bom); err != nil {
+		return fmt.Errorf("failed to encode SBOM as CycloneDX JSON: %w", err)
+	}
+
+	return nil
+}
+
+// CycloneDXXML exports SBOM as a CycloneDX XML document to the provided writer
+func CycloneDXXML(s *sbom.SBOM, writer io.Writer) error {
+	bom, err := newCycloneDXBOM(s)
+	if err != nil {
+		return err
+	}
+
+	// Write XML header first
+	if _, err := writer.Write([]byte(xml.Header)); err != nil {
+		return fmt.Errorf("failed to write XML header: %w", err)
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ") // Pretty print with 2-space indentation
+
+	if err := encoder.Encode(bom); err != nil {
+		return fmt.Errorf("failed to encode SBOM as CycloneDX XML: %w", err)
+	}
+
+	return nil
+}
+
+// newCycloneDXBOM converts an SBOM into a CycloneDX document
+func newCycloneDXBOM(s *sbom.SBOM) (*cdxBOM, error) {
+	serial, err := cycloneDXSerialNumber(s)
+	if err != nil {
+		return nil, err
+	}
+
+	bom := &cdxBOM{
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  cycloneDXSpecVersion,
+		SerialNumber: serial,
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: s.Generated,
+			Tools: cdxTools{
+				Components: []cdxComponent{
+					{Type: "application", Name: s.Tool, Version: s.Version},
+				},
+			},
+		},
+		Components: make([]cdxComponent, 0, len(s.Modules)+len(s.Providers)),
+	}
+
+	for _, module := range s.Modules {
+		bom.Components = append(bom.Components, newCycloneDXComponent(module))
+	}
+	bom.Dependencies = moduleDependencies(s.Modules, bom.Components)
+	for _, provider := range s.Providers {
+		bom.Components = append(bom.Components, newCycloneDXProviderComponent(provider))
+	}
+
+	return bom, nil
+}
+
+// newCycloneDXComponent maps a Terraform module call to a CycloneDX component.
+// Modules sourced from local paths are part of the configuration itself and are
+// reported as applications; everything else is a reusable library.
+func newCycloneDXComponent(module sbom.ModuleInfo) cdxComponent {
+	componentType := "library"
+	if sbom.IsLocalSource(module.Source) {
+		componentType = "application"
+	}
+
+	line := module.StartLine()
+
+	component := cdxComponent{
+		Type:    componentType,
+		BOMRef:  moduleRef(module, line),
+		Name:    module.Name,
+		Version: module.Version,
+		PURL:    module.PURL,
+		Properties: []cdxProperty{
+			{Name: "terraform:module:source", Value: module.Source},
+		},
+	}
+	component.Properties = append(component.Properties, sourceProperties(moduleSource(module))...)
+	if module.VersionConstraint != "" {
+		component.Properties = append(component.Properties, cdxProperty{Name: "terraform:module:version_constraint", Value: module.VersionConstraint})
+	}
+	if module.InstalledDir != "" {
+		component.Properties = append(component.Properties, cdxProperty{Name: "terraform:module:installed_dir", Value: module.InstalledDir})
+	}
+
+	if module.Filename != "" {
+		component.Evidence = &cdxEvidence{
+			Occurrences: []cdxOccurrence{{Location: module.Filename, Line: line}},
+		}
+	}
+
+	return component
+}
+
+// newCycloneDXProviderComponent maps a required provider to a CycloneDX component
+func newCycloneDXProviderComponent(provider sbom.ProviderInfo) cdxComponent {
+	component := cdxComponent{
+		Type:    "library",
+		BOMRef:  fmt.Sprintf("provider.%s@%s", provider.Name, provider.Directory),
+		Name:    provider.Name,
+		Version: provider.EffectiveVersion(),
+		Properties: []cdxProperty{
+			{Name: "terraform:provider:source", Value: provider.Source},
+		},
+	}
+
+	// Once locked the version is exact, so keep the declared constraint and lock hashes alongside it
+	if provider.Version != "" {
+		if constraint := provider.Constraint(); constraint != "" {
+			component.Properties = append(component.Properties, cdxProperty{Name: "terraform:provider:version_constraint", Value: constraint})
+		}
+		for _, hash := range provider.Hashes {
+			component.Properties = append(component.Properties, cdxProperty{Name: "terraform:provider:hash", Value: hash})
+		}
+	}
+
+	if provider.Filename != "" {
+		component.Evidence = &cdxEvidence{
+			Occurrences: []cdxOccurrence{{Location: provider.Filename, Line: provider.StartLine()}},
+		}
+	}
+
+	return component
+}
+
+// moduleDependencies links each module component to the components of the module
+// calls it makes, in the order the parents appear
+func moduleDependencies(modules []sbom.ModuleInfo, components []cdxComponent) []cdxDependency {
+	var dependencies []cdxDependency
+	index := make(map[int]int)
+	for i, parent := range moduleParents(modules) {
+		if parent < 0 {
+			continue
+		}
+		pos, ok := index[parent]
+		if !ok {
+			pos = len(dependencies)
+			index[parent] = pos
+			dependencies = append(dependencies, cdxDependency{Ref: components[parent].BOMRef})
+		}
+		dependencies[pos].DependsOn = append(dependencies[pos].DependsOn, components[i].BOMRef)
+		dependencies[pos].Children = append(dependencies[pos].Children, cdxDependencyRef{Ref: components[i].BOMRef})
+	}
+	return dependencies
+}
+
+// sourceProperties describes the parsed parts of a module source as CycloneDX
+// properties, omitting parts the source does not have
+func sourceProperties(source sbom.ModuleSource) []cdxProperty {
+	fields := []struct{ name, value string }{
+		{"kind", string(source.Kind)},
+		{"host", source.Host},
+		{"namespace", source.Namespace},
+		{"name", source.Name},
+		{"provider", source.Provider},
+		{"subdirectory", source.Subdirectory},
+		{"ref", source.Ref},
+		{"archive_format", source.ArchiveFormat},
+		{"query", source.QueryString()},
+	}
+
+	var properties []cdxProperty
+	for _, field := range fields {
+		if field.value != "" {
+			properties = append(properties, cdxProperty{Name: "terraform:module:source:" + field.name, Value: field.value})
+		}
+	}
+	return properties
+}
+
+// moduleRef builds an identifier for a module call that is unique within a single SBOM.
+// The address of a call is unique within its root configuration, unlike its file and
+// line, which are shared by every call of a module used from several places.
+func moduleRef(module sbom.ModuleInfo, line int) string {
+	if module.Path != "" {
+		if module.Root == "" {
+			return module.Path
+		}
+		return module.Path + "@" + module.Root
+	}
+	if module.Filename == "" {
+		return "module." + module.Name
+	}
+	return fmt.Sprintf("module.%s@%s:%d", module.Name, module.Filename, line)
+}
+
+// cycloneDXSerialNumber returns the serial number URN identifying the SBOM's CycloneDX document
+func cycloneDXSerialNumber(s *sbom.SBOM) (string, error) {
+	id, err := documentUUID(s)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute CycloneDX serial number: %w", err)
+	}
+	return "urn:uuid:" + id, nil
+}
@@ -0,0 +1,529 @@
+package export
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportCycloneDXJSONErrors(t *testing.T) {
+	t.Run("write error", func(t *testing.T) {
+		testSBOM := &sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "test", Source: "test", Version: "1.0", Location: "test", Filename: "/project/test.tf"},
+			},
+		}
+
+		err := CycloneDXJSON(testSBOM, &failingWriter{})
+		if err == nil {
+			t.Error("CycloneDXJSON() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to encode SBOM as CycloneDX JSON") {
+			t.Errorf("error message = %v, want 'failed to encode SBOM as CycloneDX JSON'", err.Error())
+		}
+	})
+}
+
+func TestExportCycloneDXJSON(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{
+				Name:     "vpc",
+				Source:   "terraform-aws-modules/vpc/aws",
+				Version:  "5.1.0",
+				Location: "Module call at /project/main.tf:10",
+				Filename: "/project/main.tf",
+				PURL:     "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws",
+			},
+			{
+				Name:     "local-module",
+				Source:   "./modules/local",
+				Version:  "",
+				Location: "Module call at /project/main.tf:20",
+				Filename: "/project/main.tf",
+			},
+		},
+	}
+
+	t.Run("document structure", func(t *testing.T) {
+		var buffer strings.Builder
+		if err := CycloneDXJSON(testSBOM, &buffer); err != nil {
+			t.Fatalf("CycloneDXJSON() = %v, want nil", err)
+		}
+
+		var bom cdxBOM
+		if err := json.Unmarshal([]byte(buffer.String()), &bom); err != nil {
+			t.Fatalf("failed to parse CycloneDX JSON output: %v", err)
+		}
+
+		if bom.BOMFormat != "CycloneDX" {
+			t.Errorf("bomFormat = %v, want 'CycloneDX'", bom.BOMFormat)
+		}
+		if bom.SpecVersion != "1.6" {
+			t.Errorf("specVersion = %v, want '1.6'", bom.SpecVersion)
+		}
+		if !strings.HasPrefix(bom.SerialNumber, "urn:uuid:") {
+			t.Errorf("serialNumber = %v, want urn:uuid: prefix", bom.SerialNumber)
+		}
+		if bom.Version != 1 {
+			t.Errorf("version = %v, want 1", bom.Version)
+		}
+		if bom.Metadata.Timestamp != "2024-01-02T03:04:05Z" {
+			t.Errorf("metadata.timestamp = %v, want '2024-01-02T03:04:05Z'", bom.Metadata.Timestamp)
+		}
+		if len(bom.Components) != 2 {
+			t.Fatalf("len(components) = %v, want 2", len(bom.Components))
+		}
+	})
+
+	t.Run("metadata tools", func(t *testing.T) {
+		bom, err := newCycloneDXBOM(testSBOM)
+		if err != nil {
+			t.Fatalf("newCycloneDXBOM() = %v, want nil", err)
+		}
+
+		tools := bom.Metadata.Tools.Components
+		if len(tools) != 1 {
+			t.Fatalf("len(metadata.tools.components) = %v, want 1", len(tools))
+		}
+		if tools[0].Name != "terraform-sbom" {
+			t.Errorf("tool name = %v, want 'terraform-sbom'", tools[0].Name)
+		}
+		if tools[0].Version != "1.0" {
+			t.Errorf("tool version = %v, want '1.0'", tools[0].Version)
+		}
+	})
+
+	t.Run("component mapping", func(t *testing.T) {
+		bom, err := newCycloneDXBOM(testSBOM)
+		if err != nil {
+			t.Fatalf("newCycloneDXBOM() = %v, want nil", err)
+		}
+
+		vpc := bom.Components[0]
+		if vpc.Type != "library" {
+			t.Errorf("vpc.Type = %v, want 'library'", vpc.Type)
+		}
+		if vpc.BOMRef != "module.vpc@/project/main.tf:10" {
+			t.Errorf("vpc.BOMRef = %v, want 'module.vpc@/project/main.tf:10'", vpc.BOMRef)
+		}
+		if vpc.Version != "5.1.0" {
+			t.Errorf("vpc.Version = %v, want '5.1.0'", vpc.Version)
+		}
+		if vpc.PURL != "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws" {
+			t.Errorf("vpc.PURL = %v, want 'pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws'", vpc.PURL)
+		}
+		if vpc.Evidence == nil || len(vpc.Evidence.Occurrences) != 1 {
+			t.Fatal("vpc should have exactly one evidence occurrence")
+		}
+		occurrence := vpc.Evidence.Occurrences[0]
+		if occurrence.Location != "/project/main.tf" || occurrence.Line != 10 {
+			t.Errorf("occurrence = %+v, want /project/main.tf line 10", occurrence)
+		}
+
+		properties := make(map[string]string)
+		for _, property := range vpc.Properties {
+			properties[property.Name] = property.Value
+		}
+		wantProperties := map[string]string{
+			"terraform:module:source":           "terraform-aws-modules/vpc/aws",
+			"terraform:module:source:kind":      "registry",
+			"terraform:module:source:host":      "registry.terraform.io",
+			"terraform:module:source:namespace": "terraform-aws-modules",
+			"terraform:module:source:name":      "vpc",
+			"terraform:module:source:provider":  "aws",
+		}
+		for name, want := range wantProperties {
+			if properties[name] != want {
+				t.Errorf("property %s = %q, want %q", name, properties[name], want)
+			}
+		}
+		if _, ok := properties["terraform:module:source:ref"]; ok {
+			t.Error("empty source parts should not be emitted as properties")
+		}
+
+		local := bom.Components[1]
+		if local.Type != "application" {
+			t.Errorf("local.Type = %v, want 'application'", local.Type)
+		}
+		if local.PURL != "" {
+			t.Errorf("local.PURL = %v, want empty", local.PURL)
+		}
+	})
+
+	t.Run("serial number is stable", func(t *testing.T) {
+		first, err := cycloneDXSerialNumber(testSBOM)
+		if err != nil {
+			t.Fatalf("cycloneDXSerialNumber() = %v, want nil", err)
+		}
+		second, err := cycloneDXSerialNumber(testSBOM)
+		if err != nil {
+			t.Fatalf("cycloneDXSerialNumber() = %v, want nil", err)
+		}
+		if first != second {
+			t.Errorf("serial numbers differ: %v != %v", first, second)
+		}
+		if len(first) != len("urn:uuid:")+36 {
+			t.Errorf("serial number %v is not a well-formed UUID URN", first)
+		}
+	})
+
+	t.Run("empty SBOM", func(t *testing.T) {
+		var buffer strings.Builder
+		if err := CycloneDXJSON(&sbom.SBOM{Modules: []sbom.ModuleInfo{}}, &buffer); err != nil {
+			t.Fatalf("CycloneDXJSON() = %v, want nil", err)
+		}
+		if !strings.Contains(buffer.String(), `"components": []`) {
+			t.Error("empty SBOM should produce an empty components array")
+		}
+	})
+}
+
+// validateAgainstSchema validates an XML document against a vendored XSD using xmllint
+func validateAgainstSchema(t *testing.T, schemaPath string, document string) {
+	t.Helper()
+
+	xmllint, err := exec.LookPath("xmllint")
+	if err != nil {
+		t.Skip("xmllint not available, skipping schema validation")
+	}
+
+	documentPath := filepath.Join(t.TempDir(), "document.xml")
+	if err := os.WriteFile(documentPath, []byte(document), 0644); err != nil {
+		t.Fatalf("failed to write XML document: %v", err)
+	}
+
+	output, err := exec.Command(xmllint, "--noout", "--schema", schemaPath, documentPath).CombinedOutput()
+	if err != nil {
+		t.Errorf("document does not validate against %s: %v\n%s\n%s", schemaPath, err, output, document)
+	}
+}
+
+func TestExportCycloneDXXMLErrors(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "test", Source: "test", Version: "1.0", Location: "test", Filename: "/project/test.tf"},
+		},
+	}
+
+	t.Run("XML header write error", func(t *testing.T) {
+		err := CycloneDXXML(testSBOM, &failingWriter{})
+		if err == nil {
+			t.Error("CycloneDXXML() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to write XML header") {
+			t.Errorf("error message = %v, want 'failed to write XML header'", err.Error())
+		}
+	})
+
+	t.Run("XML encoding error", func(t *testing.T) {
+		err := CycloneDXXML(testSBOM, &headerWrittenFailingWriter{})
+		if err == nil {
+			t.Error("CycloneDXXML() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to encode SBOM as CycloneDX XML") {
+			t.Errorf("error message = %v, want 'failed to encode SBOM as CycloneDX XML'", err.Error())
+		}
+	})
+}
+
+func TestExportCycloneDXXML(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{
+				Name:     "vpc",
+				Source:   "terraform-aws-modules/vpc/aws",
+				Version:  "5.1.0",
+				Location: "Module call at /project/main.tf:10",
+				Filename: "/project/main.tf",
+				PURL:     "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws",
+			},
+			{
+				Name:     "local-module",
+				Source:   "./modules/local",
+				Version:  "",
+				Location: "Module call at /project/main.tf:20",
+				Filename: "/project/main.tf",
+			},
+		},
+	}
+
+	t.Run("namespace and root element", func(t *testing.T) {
+		var buffer strings.Builder
+		if err := CycloneDXXML(testSBOM, &buffer); err != nil {
+			t.Fatalf("CycloneDXXML() = %v, want nil", err)
+		}
+
+		xmlStr := buffer.String()
+		if !strings.HasPrefix(xmlStr, xml.Header) {
+			t.Error("CycloneDX XML output should start with XML declaration header")
+		}
+		if !strings.Contains(xmlStr, `<bom xmlns="`+cycloneDXNamespace+`"`) {
+			t.Errorf("CycloneDX XML should declare the %s namespace on <bom>", cycloneDXNamespace)
+		}
+
+		var bom cdxBOM
+		if err := xml.Unmarshal([]byte(xmlStr), &bom); err != nil {
+			t.Fatalf("failed to parse CycloneDX XML output: %v", err)
+		}
+		if bom.XMLName.Space != cycloneDXNamespace {
+			t.Errorf("root namespace = %v, want %v", bom.XMLName.Space, cycloneDXNamespace)
+		}
+		if len(bom.Components) != 2 {
+			t.Fatalf("len(components) = %v, want 2", len(bom.Components))
+		}
+		if bom.Components[0].BOMRef != "module.vpc@/project/main.tf:10" {
+			t.Errorf("components[0].BOMRef = %v, want 'module.vpc@/project/main.tf:10'", bom.Components[0].BOMRef)
+		}
+		if bom.Components[0].Evidence == nil || bom.Components[0].Evidence.Occurrences[0].Line != 10 {
+			t.Error("components[0] should carry an evidence occurrence at line 10")
+		}
+		if bom.Metadata.Tools.Components[0].Name != "terraform-sbom" {
+			t.Errorf("metadata tool name = %v, want 'terraform-sbom'", bom.Metadata.Tools.Components[0].Name)
+		}
+	})
+
+	schemaPath := filepath.Join("testdata", "schema", "bom-1.6.xsd")
+
+	tests := []struct {
+		name string
+		sbom *sbom.SBOM
+	}{
+		{"modules", testSBOM},
+		{"empty SBOM", &sbom.SBOM{Version: "1.0", Generated: "2024-01-02T03:04:05Z", Tool: "terraform-sbom", Modules: []sbom.ModuleInfo{}}},
+		{"missing timestamp and filename", &sbom.SBOM{
+			Tool: "terraform-sbom",
+			Modules: []sbom.ModuleInfo{
+				{Name: "orphan", Source: "hashicorp/consul/aws"},
+			},
+		}},
+		{"providers", &sbom.SBOM{
+			Version:   "1.0",
+			Generated: "2024-01-02T03:04:05Z",
+			Tool:      "terraform-sbom",
+			Modules:   []sbom.ModuleInfo{},
+			Providers: []sbom.ProviderInfo{
+				{
+					Name:               "aws",
+					Source:             "registry.terraform.io/hashicorp/aws",
+					VersionConstraints: []string{"~> 5.0"},
+					Location:           "Provider requirement at /project/versions.tf:4",
+					Filename:           "/project/versions.tf",
+					Directory:          "/project",
+				},
+			},
+		}},
+		{"nested modules", nestedModulesSBOM},
+		{"characters requiring escaping", &sbom.SBOM{
+			Version:   "1.0",
+			Generated: "2024-01-02T03:04:05+02:00",
+			Tool:      "terraform-sbom",
+			Modules: []sbom.ModuleInfo{
+				{
+					Name:     "a&b<c>",
+					Source:   "git::https://example.com/repo.git?ref=v1.0.0&depth=1",
+					Version:  "\">= 1.0\"",
+					Location: "Module call at /project/weird & name.tf:3",
+					Filename: "/project/weird & name.tf",
+				},
+			},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run("schema validation "+test.name, func(t *testing.T) {
+			var buffer strings.Builder
+			if err := CycloneDXXML(test.sbom, &buffer); err != nil {
+				t.Fatalf("CycloneDXXML() = %v, want nil", err)
+			}
+			validateAgainstSchema(t, schemaPath, buffer.String())
+		})
+	}
+}
+
+func TestCycloneDXProviderComponent(t *testing.T) {
+	provider := sbom.ProviderInfo{
+		Name:               "aws",
+		Source:             "registry.terraform.io/hashicorp/aws",
+		VersionConstraints: []string{">= 4.0", "< 6.0"},
+		Location:           "Provider requirement at /project/versions.tf:4",
+		Filename:           "/project/versions.tf",
+		Directory:          "/project",
+	}
+
+	component := newCycloneDXProviderComponent(provider)
+	if component.Type != "library" {
+		t.Errorf("component.Type = %v, want 'library'", component.Type)
+	}
+	if component.BOMRef != "provider.aws@/project" {
+		t.Errorf("component.BOMRef = %v, want 'provider.aws@/project'", component.BOMRef)
+	}
+	if component.Version != ">= 4.0, < 6.0" {
+		t.Errorf("component.Version = %v, want '>= 4.0, < 6.0'", component.Version)
+	}
+	if len(component.Properties) != 1 || component.Properties[0].Value != "registry.terraform.io/hashicorp/aws" {
+		t.Errorf("component.Properties = %+v, want the provider source", component.Properties)
+	}
+	if component.Evidence == nil || component.Evidence.Occurrences[0].Line != 4 {
+		t.Error("component should carry an evidence occurrence at line 4")
+	}
+}
+
+func TestCycloneDXLockedProviderComponent(t *testing.T) {
+	provider := sbom.ProviderInfo{
+		Name:               "aws",
+		Source:             "registry.terraform.io/hashicorp/aws",
+		VersionConstraints: []string{"~> 5.0"},
+		Directory:          "/project",
+		Version:            "5.31.0",
+		Hashes:             []string{"h1:abc=", "zh:0123"},
+		LockFile:           "/project/.terraform.lock.hcl",
+	}
+
+	component := newCycloneDXProviderComponent(provider)
+	if component.Version != "5.31.0" {
+		t.Errorf("component.Version = %v, want '5.31.0'", component.Version)
+	}
+
+	expected := []cdxProperty{
+		{Name: "terraform:provider:source", Value: "registry.terraform.io/hashicorp/aws"},
+		{Name: "terraform:provider:version_constraint", Value: "~> 5.0"},
+		{Name: "terraform:provider:hash", Value: "h1:abc="},
+		{Name: "terraform:provider:hash", Value: "zh:0123"},
+	}
+	if !reflect.DeepEqual(component.Properties, expected) {
+		t.Errorf("component.Properties = %+v, want %+v", component.Properties, expected)
+	}
+}
+
+func TestCycloneDXResolvedModuleComponent(t *testing.T) {
+	module := sbom.ModuleInfo{
+		Name:              "vpc",
+		Source:            "terraform-aws-modules/vpc/aws",
+		Version:           "5.1.2",
+		VersionConstraint: ">= 3.0",
+		InstalledDir:      "/project/.terraform/modules/vpc",
+	}
+
+	component := newCycloneDXComponent(module)
+	if component.Version != "5.1.2" {
+		t.Errorf("component.Version = %v, want '5.1.2'", component.Version)
+	}
+
+	properties := make(map[string]string)
+	for _, property := range component.Properties {
+		properties[property.Name] = property.Value
+	}
+	if properties["terraform:module:version_constraint"] != ">= 3.0" {
+		t.Errorf("version_constraint property = %q, want '>= 3.0'", properties["terraform:module:version_constraint"])
+	}
+	if properties["terraform:module:installed_dir"] != "/project/.terraform/modules/vpc" {
+		t.Errorf("installed_dir property = %q, want '/project/.terraform/modules/vpc'", properties["terraform:module:installed_dir"])
+	}
+}
+
+// nestedModulesSBOM is a transitive scan of root -> network -> vpc, plus a second direct call
+var nestedModulesSBOM = &sbom.SBOM{
+	Version:   "1.0",
+	Generated: "2024-01-02T03:04:05Z",
+	Tool:      "terraform-sbom",
+	Modules: []sbom.ModuleInfo{
+		{Name: "network", Source: "./modules/network", Location: "Module call at /project/main.tf:1", Filename: "/project/main.tf", Root: "/project", Path: "module.network", Depth: 1},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "~> 5.0", Location: "Module call at /project/modules/network/main.tf:1", Filename: "/project/modules/network/main.tf", Root: "/project", Path: "module.network.module.vpc", Parent: "module.network", Depth: 2},
+		{Name: "dns", Source: "./modules/dns", Location: "Module call at /project/main.tf:5", Filename: "/project/main.tf", Root: "/project", Path: "module.dns", Depth: 1},
+	},
+}
+
+func TestCycloneDXModuleDependencies(t *testing.T) {
+	bom, err := newCycloneDXBOM(nestedModulesSBOM)
+	if err != nil {
+		t.Fatalf("newCycloneDXBOM() = %v, want nil", err)
+	}
+
+	expected := []cdxDependency{
+		{
+			Ref:       "module.network@/project",
+			DependsOn: []string{"module.network.module.vpc@/project"},
+			Children:  []cdxDependencyRef{{Ref: "module.network.module.vpc@/project"}},
+		},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, expected) {
+		t.Errorf("Dependencies = %+v, want %+v", bom.Dependencies, expected)
+	}
+
+	var buffer strings.Builder
+	if err := CycloneDXJSON(nestedModulesSBOM, &buffer); err != nil {
+		t.Fatalf("CycloneDXJSON() = %v, want nil", err)
+	}
+	if !strings.Contains(buffer.String(), `"dependsOn": [`) {
+		t.Errorf("CycloneDX JSON should list dependsOn refs, got:\n%s", buffer.String())
+	}
+
+	buffer.Reset()
+	if err := CycloneDXXML(nestedModulesSBOM, &buffer); err != nil {
+		t.Fatalf("CycloneDXXML() = %v, want nil", err)
+	}
+	var doc struct {
+		Dependencies []struct {
+			Ref      string `xml:"ref,attr"`
+			Children []struct {
+				Ref string `xml:"ref,attr"`
+			} `xml:"dependency"`
+		} `xml:"dependencies>dependency"`
+	}
+	if err := xml.Unmarshal([]byte(buffer.String()), &doc); err != nil {
+		t.Fatalf("failed to parse CycloneDX XML output: %v", err)
+	}
+	if len(doc.Dependencies) != 1 || len(doc.Dependencies[0].Children) != 1 || doc.Dependencies[0].Children[0].Ref != "module.network.module.vpc@/project" {
+		t.Errorf("CycloneDX XML should nest dependency refs, got:\n%s", buffer.String())
+	}
+}
+
+func TestCycloneDXSharedModuleRefs(t *testing.T) {
+	// Two calls of the same local module make the vpc call from the same file and line
+	shared := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{Name: "net", Source: "./modules/net", Filename: "/project/main.tf", Line: 1, Root: "/project", Path: "module.net", Depth: 1},
+			{Name: "net2", Source: "./modules/net", Filename: "/project/main.tf", Line: 5, Root: "/project", Path: "module.net2", Depth: 1},
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Filename: "/project/modules/net/main.tf", Line: 1, Root: "/project", Path: "module.net.module.vpc", Parent: "module.net", Depth: 2},
+			{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Filename: "/project/modules/net/main.tf", Line: 1, Root: "/project", Path: "module.net2.module.vpc", Parent: "module.net2", Depth: 2},
+		},
+	}
+
+	bom, err := newCycloneDXBOM(shared)
+	if err != nil {
+		t.Fatalf("newCycloneDXBOM() = %v, want nil", err)
+	}
+
+	refs := make(map[string]bool)
+	for _, component := range bom.Components {
+		if refs[component.BOMRef] {
+			t.Errorf("bom-ref %q is used by more than one component", component.BOMRef)
+		}
+		refs[component.BOMRef] = true
+	}
+
+	expected := []cdxDependency{
+		{Ref: "module.net@/project", DependsOn: []string{"module.net.module.vpc@/project"}, Children: []cdxDependencyRef{{Ref: "module.net.module.vpc@/project"}}},
+		{Ref: "module.net2@/project", DependsOn: []string{"module.net2.module.vpc@/project"}, Children: []cdxDependencyRef{{Ref: "module.net2.module.vpc@/project"}}},
+	}
+	if !reflect.DeepEqual(bom.Dependencies, expected) {
+		t.Errorf("Dependencies = %+v, want %+v", bom.Dependencies, expected)
+	}
+}
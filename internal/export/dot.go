@@ -0,0 +1,60 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// dotNodeStyles maps graph node kinds to Graphviz node attributes
+var dotNodeStyles = map[string]string{
+	graphRoot:     `shape=folder, style=filled, fillcolor="#d9d9d9"`,
+	graphProvider: `shape=hexagon, style=filled, fillcolor="#fff2cc"`,
+	graphLocal:    `shape=box, style="rounded,filled", fillcolor="#d9ead3"`,
+	graphRegistry: `shape=box, style="rounded,filled", fillcolor="#cfe2f3"`,
+	graphVCS:      `shape=box, style="rounded,filled", fillcolor="#fce5cd"`,
+	graphArchive:  `shape=box, style="rounded,filled", fillcolor="#ead1dc"`,
+	graphUnknown:  `shape=box, style="rounded,dashed"`,
+}
+
+// DOT exports the SBOM dependency graph in Graphviz DOT format to the provided writer
+func DOT(s *sbom.SBOM, writer io.Writer) error {
+	g := newDependencyGraph(s)
+	w := bufio.NewWriter(writer)
+
+	fmt.Fprintln(w, "digraph terraform {")
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, `  node [fontname="Helvetica"];`)
+	fmt.Fprintln(w, `  edge [fontname="Helvetica", fontsize=10];`)
+	fmt.Fprintln(w)
+	for _, node := range g.Nodes {
+		fmt.Fprintf(w, "  %s [label=%s, %s];\n", node.ID, dotQuote(node.Label), dotNodeStyles[node.Kind])
+	}
+	fmt.Fprintln(w)
+	for _, edge := range g.Edges {
+		if edge.Label != "" {
+			fmt.Fprintf(w, "  %s -> %s [label=%s];\n", edge.From, edge.To, dotQuote(edge.Label))
+		} else {
+			fmt.Fprintf(w, "  %s -> %s;\n", edge.From, edge.To)
+		}
+	}
+	fmt.Fprintln(w, "}")
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write DOT graph: %w", err)
+	}
+
+	return nil
+}
+
+// dotQuote returns value as a quoted DOT string with line breaks rendered as new lines
+func dotQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	value = strings.ReplaceAll(value, "\r", "")
+	value = strings.ReplaceAll(value, "\n", `\n`)
+	return `"` + value + `"`
+}
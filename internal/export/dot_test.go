@@ -0,0 +1,66 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportDOT(t *testing.T) {
+	var buffer strings.Builder
+	if err := DOT(graphSBOM, &buffer); err != nil {
+		t.Fatalf("DOT() = %v, want nil", err)
+	}
+	output := buffer.String()
+
+	expected := []string{
+		"digraph terraform {",
+		`  root0 [label="/project", shape=folder`,
+		`  module1 [label="vpc\nterraform-aws-modules/vpc/aws\n5.1.2", shape=box, style="rounded,filled", fillcolor="#cfe2f3"];`,
+		`  module2 [label="app\ngit::https://example.com/app.git?ref=v1.0.0", shape=box, style="rounded,filled", fillcolor="#fce5cd"];`,
+		`  provider0 [label="aws\nregistry.terraform.io/hashicorp/aws\n5.31.0", shape=hexagon`,
+		"  root0 -> module0;",
+		`  module0 -> module1 [label="~> 5.0"];`,
+		`  module1 -> provider0 [label=">= 4.0"];`,
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("DOT output missing %q:\n%s", want, output)
+		}
+	}
+	if !strings.HasSuffix(output, "}\n") {
+		t.Errorf("DOT output does not end the graph:\n%s", output)
+	}
+}
+
+func TestExportDOTEscaping(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "weird", Source: `./a "quoted" \ path`, Version: `">= 1.0"`, Filename: "/project/main.tf", Root: "/project", Path: "module.weird", Depth: 1},
+		},
+	}
+
+	var buffer strings.Builder
+	if err := DOT(testSBOM, &buffer); err != nil {
+		t.Fatalf("DOT() = %v, want nil", err)
+	}
+	output := buffer.String()
+
+	if !strings.Contains(output, `label="weird\n./a \"quoted\" \\ path"`) {
+		t.Errorf("DOT output does not escape the node label:\n%s", output)
+	}
+	if !strings.Contains(output, `[label="\">= 1.0\""]`) {
+		t.Errorf("DOT output does not escape the edge label:\n%s", output)
+	}
+}
+
+func TestExportDOTErrors(t *testing.T) {
+	err := DOT(graphSBOM, &failingWriter{})
+	if err == nil {
+		t.Fatal("DOT() = nil, want error for failing writer")
+	}
+	if !strings.Contains(err.Error(), "failed to write DOT graph") {
+		t.Errorf("error message = %v, want 'failed to write DOT graph'", err.Error())
+	}
+}
@@ -1,6 +1,8 @@
 package export
 
 import (
+	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -39,8 +41,26 @@ func Export(s *sbom.SBOM, format string, outputPath string) error {
 		return CSV(s, file)
 	case "tsv":
 		return TSV(s, file)
+	case "cyclonedx-json":
+		return CycloneDXJSON(s, file)
+	case "cyclonedx-xml":
+		return CycloneDXXML(s, file)
+	case "spdx-json":
+		return SPDXJSON(s, file)
+	case "spdx":
+		return SPDXTagValue(s, file)
+	case "spdx3":
+		return SPDX3(s, file)
+	case "dot":
+		return DOT(s, file)
+	case "mermaid":
+		return Mermaid(s, file)
+	case "openvex":
+		return OpenVEX(s, file)
+	case "sarif":
+		return SARIF(s, file)
 	default:
-		return fmt.Errorf("unsupported format: %s (supported: json, xml, csv, tsv)", format)
+		return fmt.Errorf("unsupported format: %s (supported: json, xml, csv, tsv, cyclonedx-json, cyclonedx-xml, spdx-json, spdx, spdx3, dot, mermaid, openvex, sarif)", format)
 	}
 }
 
@@ -57,6 +77,24 @@ func GenerateOutputFilename(baseOutput, format string) string {
 			return "sbom.csv"
 		case "tsv":
 			return "sbom.tsv"
+		case "cyclonedx-json":
+			return "sbom.cdx.json"
+		case "cyclonedx-xml":
+			return "sbom.cdx.xml"
+		case "spdx-json":
+			return "sbom.spdx.json"
+		case "spdx":
+			return "sbom.spdx"
+		case "spdx3":
+			return "sbom.spdx3.json"
+		case "dot":
+			return "sbom.dot"
+		case "mermaid":
+			return "sbom.mmd"
+		case "openvex":
+			return "sbom.openvex.json"
+		case "sarif":
+			return "sbom.sarif"
 		default:
 			return "sbom.json"
 		}
@@ -75,7 +113,71 @@ func GenerateOutputFilename(baseOutput, format string) string {
 		return base + ".csv"
 	case "tsv":
 		return base + ".tsv"
+	case "cyclonedx-json":
+		return base + ".cdx.json"
+	case "cyclonedx-xml":
+		return base + ".cdx.xml"
+	case "spdx-json":
+		return base + ".spdx.json"
+	case "spdx":
+		return base + ".spdx"
+	case "spdx3":
+		return base + ".spdx3.json"
+	case "dot":
+		return base + ".dot"
+	case "mermaid":
+		return base + ".mmd"
+	case "openvex":
+		return base + ".openvex.json"
+	case "sarif":
+		return base + ".sarif"
 	default:
 		return base + ".json"
 	}
 }
+
+// documentUUID derives a stable RFC 4122 UUID from the SBOM contents so that
+// standard-format documents generated from the same SBOM share the same identifier
+func documentUUID(s *sbom.SBOM) (string, error) {
+	content, err := json.Marshal(s)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha1.Sum(content)
+	sum[6] = (sum[6] & 0x0f) | 0x50 // version 5 (name-based, SHA-1)
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16]), nil
+}
+
+// moduleSource returns the parsed form of a module's source address, parsing it on
+// demand for SBOMs that were built without one
+func moduleSource(module sbom.ModuleInfo) sbom.ModuleSource {
+	if module.ParsedSource != nil {
+		return *module.ParsedSource
+	}
+	return sbom.ParseSource(module.Source)
+}
+
+// moduleParents returns, for each module, the index of the module call that loaded it
+// or -1 for calls made directly by a root configuration
+func moduleParents(modules []sbom.ModuleInfo) []int {
+	type key struct{ root, path string }
+
+	byPath := make(map[key]int, len(modules))
+	for i, module := range modules {
+		if module.Path != "" {
+			byPath[key{module.Root, module.Path}] = i
+		}
+	}
+
+	parents := make([]int, len(modules))
+	for i, module := range modules {
+		parents[i] = -1
+		if idx, ok := byPath[key{module.Root, module.Parent}]; ok && module.Parent != "" {
+			parents[i] = idx
+		}
+	}
+	return parents
+}
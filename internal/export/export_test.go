@@ -30,6 +30,16 @@ func TestExportSBOM(t *testing.T) {
 				Filename: "/project/main.tf",
 			},
 		},
+		Providers: []sbom.ProviderInfo{
+			{
+				Name:               "aws",
+				Source:             "registry.terraform.io/hashicorp/aws",
+				VersionConstraints: []string{"~> 5.0"},
+				Location:           "Provider requirement at /project/versions.tf:4",
+				Filename:           "/project/versions.tf",
+				Directory:          "/project",
+			},
+		},
 	}
 
 	// Test successful JSON export
@@ -122,7 +132,7 @@ func TestExportSBOM(t *testing.T) {
 			t.Error("Export() = nil, want error for unsupported format")
 		}
 
-		expectedError := "unsupported format: yaml (supported: json, xml, csv, tsv)"
+		expectedError := "unsupported format: yaml (supported: json, xml, csv, tsv, cyclonedx-json, cyclonedx-xml, spdx-json, spdx, spdx3, dot, mermaid, openvex, sarif)"
 		if err.Error() != expectedError {
 			t.Errorf("error message = %v, want %v", err.Error(), expectedError)
 		}
@@ -160,7 +170,7 @@ func TestExportSBOM(t *testing.T) {
 			t.Fatal("CSV file should have at least a header line")
 		}
 		header := lines[0]
-		expectedHeader := "Name,Source,Version,Location,Filename"
+		expectedHeader := "Name,Source,Version,Location,Filename,PURL,SourceKind,SourceHost,SourceNamespace,SourceName,SourceProvider,SourceSubdirectory,SourceRef,SourceArchiveFormat,SourceQuery,Type,VersionConstraint,InstalledDir,Root,Path,Depth,Line,Column,FilePath"
 		if header != expectedHeader {
 			t.Errorf("CSV header = %q, want %q", header, expectedHeader)
 		}
@@ -175,12 +185,27 @@ func TestExportSBOM(t *testing.T) {
 		if !strings.Contains(firstRow, "/project/main.tf") {
 			t.Errorf("First CSV row should contain filename '/project/main.tf', got: %q", firstRow)
 		}
+		if !strings.Contains(firstRow, ",registry,registry.terraform.io,terraform-aws-modules,vpc,aws,") {
+			t.Errorf("First CSV row should contain the parsed registry source, got: %q", firstRow)
+		}
 
 		// Check second module row
 		secondRow := lines[2]
 		if !strings.Contains(secondRow, "/project/main.tf") {
 			t.Errorf("Second CSV row should contain filename '/project/main.tf', got: %q", secondRow)
 		}
+		if !strings.Contains(secondRow, ",local,,,local,") {
+			t.Errorf("Second CSV row should contain the parsed local source, got: %q", secondRow)
+		}
+
+		// Check provider row
+		if len(lines) != 4 {
+			t.Fatalf("CSV file should have header + 2 module rows + 1 provider row, got %d lines", len(lines))
+		}
+		expectedProviderRow := "aws,registry.terraform.io/hashicorp/aws,~> 5.0,Provider requirement at /project/versions.tf:4,/project/versions.tf,,registry,registry.terraform.io,hashicorp,aws,,,,,,provider,,,,,,4,,"
+		if lines[3] != expectedProviderRow {
+			t.Errorf("provider CSV row = %q, want %q", lines[3], expectedProviderRow)
+		}
 	})
 
 	t.Run("tsv format", func(t *testing.T) {
@@ -215,7 +240,7 @@ func TestExportSBOM(t *testing.T) {
 			t.Fatal("TSV file should have at least a header line")
 		}
 		header := lines[0]
-		expectedHeader := "Name\tSource\tVersion\tLocation\tFilename"
+		expectedHeader := "Name\tSource\tVersion\tLocation\tFilename\tPURL\tSourceKind\tSourceHost\tSourceNamespace\tSourceName\tSourceProvider\tSourceSubdirectory\tSourceRef\tSourceArchiveFormat\tSourceQuery\tType\tVersionConstraint\tInstalledDir\tRoot\tPath\tDepth\tLine\tColumn\tFilePath"
 		if header != expectedHeader {
 			t.Errorf("TSV header = %q, want %q", header, expectedHeader)
 		}
@@ -315,6 +340,15 @@ func TestGenerateOutputFilename(t *testing.T) {
 		}{
 			{"json", "sbom.json"},
 			{"xml", "sbom.xml"},
+			{"cyclonedx-json", "sbom.cdx.json"},
+			{"cyclonedx-xml", "sbom.cdx.xml"},
+			{"spdx-json", "sbom.spdx.json"},
+			{"spdx", "sbom.spdx"},
+			{"spdx3", "sbom.spdx3.json"},
+			{"dot", "sbom.dot"},
+			{"mermaid", "sbom.mmd"},
+			{"openvex", "sbom.openvex.json"},
+			{"sarif", "sbom.sarif"},
 			{"unknown", "sbom.json"},
 			{"", "sbom.json"},
 		}
@@ -335,6 +369,15 @@ func TestGenerateOutputFilename(t *testing.T) {
 		}{
 			{"mysbom", "json", "mysbom.json"},
 			{"mysbom", "xml", "mysbom.xml"},
+			{"mysbom", "cyclonedx-json", "mysbom.cdx.json"},
+			{"mysbom", "cyclonedx-xml", "mysbom.cdx.xml"},
+			{"mysbom", "spdx-json", "mysbom.spdx.json"},
+			{"mysbom", "spdx", "mysbom.spdx"},
+			{"mysbom", "spdx3", "mysbom.spdx3.json"},
+			{"mysbom", "dot", "mysbom.dot"},
+			{"mysbom", "mermaid", "mysbom.mmd"},
+			{"mysbom", "openvex", "mysbom.openvex.json"},
+			{"mysbom", "sarif", "mysbom.sarif"},
 			{"mysbom", "unknown", "mysbom.json"},
 			{"output", "json", "output.json"},
 		}
@@ -420,3 +463,31 @@ func TestGenerateOutputFilename(t *testing.T) {
 		}
 	})
 }
+
+func TestCSVResourceRows(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Resources: []sbom.ResourceInfo{
+			{Address: "aws_iam_role.this", Mode: sbom.ResourceModeManaged, Type: "aws_iam_role", Name: "this", Provider: "aws", ProviderSource: "registry.terraform.io/hashicorp/aws", Location: "Resource at /project/main.tf:1", Filename: "/project/main.tf", Directory: "/project"},
+			{Address: "data.aws_ami.ubuntu", Mode: sbom.ResourceModeData, Type: "aws_ami", Name: "ubuntu", Provider: "aws", ProviderSource: "registry.terraform.io/hashicorp/aws", Location: "Data source at /project/main.tf:5", Filename: "/project/main.tf", Directory: "/project"},
+		},
+	}
+
+	var buffer strings.Builder
+	if err := CSV(testSBOM, &buffer); err != nil {
+		t.Fatalf("CSV() = %v, want nil", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	expected := []string{
+		"aws_iam_role.this,registry.terraform.io/hashicorp/aws,,Resource at /project/main.tf:1,/project/main.tf,,,,,,,,,,,resource,,,,,,,,",
+		"data.aws_ami.ubuntu,registry.terraform.io/hashicorp/aws,,Data source at /project/main.tf:5,/project/main.tf,,,,,,,,,,,data,,,,,,,,",
+	}
+	if len(lines) != len(expected)+1 {
+		t.Fatalf("CSV output should have header + %d resource rows, got %d lines", len(expected), len(lines))
+	}
+	for i, want := range expected {
+		if lines[i+1] != want {
+			t.Errorf("resource CSV row %d = %q, want %q", i, lines[i+1], want)
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// Graph node kinds used to style nodes. Module nodes are styled by the kind of their
+// source, grouping related source types.
+const (
+	graphRoot     = "root"
+	graphProvider = "provider"
+	graphLocal    = "local"
+	graphRegistry = "registry"
+	graphVCS      = "vcs"
+	graphArchive  = "archive"
+	graphUnknown  = "unknown"
+)
+
+// graphNode is a root configuration, module call or provider in a dependency graph
+type graphNode struct {
+	ID    string
+	Label string
+	Kind  string
+}
+
+// graphEdge connects a configuration or module to a module call or provider it
+// depends on. Label holds the version constraint, if any.
+type graphEdge struct {
+	From  string
+	To    string
+	Label string
+}
+
+// dependencyGraph is the format-independent graph rendered by the DOT and Mermaid exports
+type dependencyGraph struct {
+	Nodes []graphNode
+	Edges []graphEdge
+}
+
+// newDependencyGraph builds the dependency graph of an SBOM. Each scanned root
+// configuration and module call gets its own node, while providers are shared by
+// every configuration or module that requires them.
+func newDependencyGraph(s *sbom.SBOM) *dependencyGraph {
+	g := &dependencyGraph{}
+
+	// Directories that belong to each root or module node, used to attach providers
+	owners := make(map[string]string)
+	var rootDirs []string
+
+	rootNode := func(dir string) string {
+		if id, ok := owners[dir]; ok {
+			return id
+		}
+		id := "root" + strconv.Itoa(len(rootDirs))
+		rootDirs = append(rootDirs, dir)
+		owners[dir] = id
+		label := dir
+		if label == "" {
+			label = "terraform-configuration"
+		}
+		g.Nodes = append(g.Nodes, graphNode{ID: id, Label: label, Kind: graphRoot})
+		return id
+	}
+
+	parents := moduleParents(s.Modules)
+	moduleIDs := make([]string, len(s.Modules))
+	for i := range s.Modules {
+		moduleIDs[i] = "module" + strconv.Itoa(i)
+	}
+
+	for i, module := range s.Modules {
+		callerDir := filepath.Dir(module.Filename)
+
+		from := ""
+		if parents[i] >= 0 {
+			from = moduleIDs[parents[i]]
+			if _, ok := owners[callerDir]; !ok {
+				owners[callerDir] = from
+			}
+		} else {
+			root := module.Root
+			if root == "" {
+				root = callerDir
+			}
+			from = rootNode(root)
+		}
+
+		source := moduleSource(module)
+		label := module.Name
+		if module.Source != "" {
+			label += "\n" + module.Source
+		}
+		if module.VersionConstraint != "" && module.Version != "" {
+			label += "\n" + module.Version
+		}
+
+		g.Nodes = append(g.Nodes, graphNode{ID: moduleIDs[i], Label: label, Kind: graphSourceKind(source.Kind)})
+		g.Edges = append(g.Edges, graphEdge{From: from, To: moduleIDs[i], Label: moduleConstraint(module)})
+
+		// The directory a module is loaded from, when known, so that its providers can
+		// be attached to it
+		var moduleDir string
+		switch {
+		case module.InstalledDir != "":
+			moduleDir = module.InstalledDir
+		case source.Kind == sbom.SourceLocal && module.Filename != "":
+			moduleDir = filepath.Join(callerDir, module.Source)
+		}
+		if _, ok := owners[moduleDir]; moduleDir != "" && !ok {
+			owners[moduleDir] = moduleIDs[i]
+		}
+	}
+
+	providerIDs := make(map[string]string)
+	for _, provider := range s.Providers {
+		id, ok := providerIDs[provider.Source]
+		if !ok {
+			id = "provider" + strconv.Itoa(len(providerIDs))
+			providerIDs[provider.Source] = id
+			label := provider.Name
+			if provider.Source != "" {
+				label += "\n" + provider.Source
+			}
+			if provider.Version != "" {
+				label += "\n" + provider.Version
+			}
+			g.Nodes = append(g.Nodes, graphNode{ID: id, Label: label, Kind: graphProvider})
+		}
+
+		g.Edges = append(g.Edges, graphEdge{From: providerOwner(owners, rootDirs, provider.Directory, rootNode), To: id, Label: provider.Constraint()})
+	}
+
+	return g
+}
+
+// providerOwner returns the node that requires providers declared in dir. Modules
+// installed below a root configuration's .terraform directory that are not otherwise
+// known fall back to that root configuration.
+func providerOwner(owners map[string]string, rootDirs []string, dir string, rootNode func(string) string) string {
+	if id, ok := owners[dir]; ok {
+		return id
+	}
+	if dir == "" && len(rootDirs) > 0 {
+		return owners[rootDirs[0]]
+	}
+
+	for _, root := range rootDirs {
+		if rel, err := filepath.Rel(root, dir); err == nil && strings.HasPrefix(rel, ".terraform"+string(filepath.Separator)) {
+			return owners[root]
+		}
+	}
+	return rootNode(dir)
+}
+
+// moduleConstraint returns the version constraint declared by a module call
+func moduleConstraint(module sbom.ModuleInfo) string {
+	if module.VersionConstraint != "" {
+		return module.VersionConstraint
+	}
+	return module.Version
+}
+
+// graphSourceKind groups module source kinds for node styling
+func graphSourceKind(kind sbom.SourceKind) string {
+	switch kind {
+	case sbom.SourceLocal:
+		return graphLocal
+	case sbom.SourceRegistry:
+		return graphRegistry
+	case sbom.SourceGitHub, sbom.SourceBitbucket, sbom.SourceGit, sbom.SourceMercurial:
+		return graphVCS
+	case sbom.SourceHTTP, sbom.SourceS3, sbom.SourceGCS:
+		return graphArchive
+	default:
+		return graphUnknown
+	}
+}
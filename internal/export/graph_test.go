@@ -0,0 +1,95 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// graphSBOM is a transitive scan of root -> network -> vpc with providers required by
+// the root configuration and by the installed vpc module
+var graphSBOM = &sbom.SBOM{
+	Version:   "1.0",
+	Generated: "2024-01-02T03:04:05Z",
+	Tool:      "terraform-sbom",
+	Modules: []sbom.ModuleInfo{
+		{Name: "network", Source: "./modules/network", Filename: "/project/main.tf", Root: "/project", Path: "module.network", Depth: 1},
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.2", VersionConstraint: "~> 5.0", Filename: "/project/modules/network/main.tf", InstalledDir: "/project/.terraform/modules/network.vpc", Root: "/project", Path: "module.network.module.vpc", Parent: "module.network", Depth: 2},
+		{Name: "app", Source: "git::https://example.com/app.git?ref=v1.0.0", Filename: "/project/main.tf", Root: "/project", Path: "module.app", Depth: 1},
+	},
+	Providers: []sbom.ProviderInfo{
+		{Name: "aws", Source: "registry.terraform.io/hashicorp/aws", VersionConstraints: []string{"~> 5.0"}, Version: "5.31.0", Directory: "/project"},
+		{Name: "aws", Source: "registry.terraform.io/hashicorp/aws", VersionConstraints: []string{">= 4.0"}, Version: "5.31.0", Directory: "/project/.terraform/modules/network.vpc"},
+		{Name: "random", Source: "registry.terraform.io/hashicorp/random", Directory: "/project/modules/network"},
+	},
+}
+
+func TestDependencyGraph(t *testing.T) {
+	g := newDependencyGraph(graphSBOM)
+
+	expectedNodes := []graphNode{
+		{ID: "root0", Label: "/project", Kind: graphRoot},
+		{ID: "module0", Label: "network\n./modules/network", Kind: graphLocal},
+		{ID: "module1", Label: "vpc\nterraform-aws-modules/vpc/aws\n5.1.2", Kind: graphRegistry},
+		{ID: "module2", Label: "app\ngit::https://example.com/app.git?ref=v1.0.0", Kind: graphVCS},
+		{ID: "provider0", Label: "aws\nregistry.terraform.io/hashicorp/aws\n5.31.0", Kind: graphProvider},
+		{ID: "provider1", Label: "random\nregistry.terraform.io/hashicorp/random", Kind: graphProvider},
+	}
+	if !reflect.DeepEqual(g.Nodes, expectedNodes) {
+		t.Errorf("Nodes = %+v, want %+v", g.Nodes, expectedNodes)
+	}
+
+	expectedEdges := []graphEdge{
+		{From: "root0", To: "module0"},
+		{From: "module0", To: "module1", Label: "~> 5.0"},
+		{From: "root0", To: "module2"},
+		{From: "root0", To: "provider0", Label: "~> 5.0"},
+		{From: "module1", To: "provider0", Label: ">= 4.0"},
+		{From: "module0", To: "provider1"},
+	}
+	if !reflect.DeepEqual(g.Edges, expectedEdges) {
+		t.Errorf("Edges = %+v, want %+v", g.Edges, expectedEdges)
+	}
+}
+
+func TestDependencyGraphRoots(t *testing.T) {
+	t.Run("one node per scanned configuration", func(t *testing.T) {
+		g := newDependencyGraph(&sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "a", Source: "./a", Filename: "/project/dev/main.tf", Root: "/project/dev", Path: "module.a", Depth: 1},
+				{Name: "a", Source: "./a", Filename: "/project/prod/main.tf", Root: "/project/prod", Path: "module.a", Depth: 1},
+			},
+			Providers: []sbom.ProviderInfo{
+				{Name: "null", Source: "registry.terraform.io/hashicorp/null", Directory: "/project/shared"},
+			},
+		})
+
+		var roots []string
+		for _, node := range g.Nodes {
+			if node.Kind == graphRoot {
+				roots = append(roots, node.Label)
+			}
+		}
+		expected := []string{"/project/dev", "/project/prod", "/project/shared"}
+		if !reflect.DeepEqual(roots, expected) {
+			t.Errorf("roots = %v, want %v", roots, expected)
+		}
+
+		// Modules with the same address in different configurations are not merged
+		if g.Edges[0].From == g.Edges[1].From {
+			t.Errorf("Edges = %+v, want each call attached to its own root", g.Edges)
+		}
+	})
+
+	t.Run("modules without a recorded root", func(t *testing.T) {
+		g := newDependencyGraph(&sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Filename: "/project/main.tf"},
+			},
+		})
+		if g.Nodes[0].Label != "/project" || g.Edges[0].From != g.Nodes[0].ID {
+			t.Errorf("graph = %+v, want the call attached to its file's directory", g)
+		}
+	})
+}
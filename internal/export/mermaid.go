@@ -0,0 +1,73 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// mermaidClasses lists the Mermaid class definitions for each graph node kind, in the
+// order they are written
+var mermaidClasses = []struct {
+	kind  string
+	style string
+}{
+	{graphRoot, "fill:#d9d9d9,stroke:#666666"},
+	{graphProvider, "fill:#fff2cc,stroke:#b7950b"},
+	{graphLocal, "fill:#d9ead3,stroke:#38761d"},
+	{graphRegistry, "fill:#cfe2f3,stroke:#0b5394"},
+	{graphVCS, "fill:#fce5cd,stroke:#b45f06"},
+	{graphArchive, "fill:#ead1dc,stroke:#741b47"},
+	{graphUnknown, "fill:#ffffff,stroke:#999999,stroke-dasharray:4 2"},
+}
+
+// Mermaid exports the SBOM dependency graph as a Mermaid flowchart to the provided writer
+func Mermaid(s *sbom.SBOM, writer io.Writer) error {
+	g := newDependencyGraph(s)
+	w := bufio.NewWriter(writer)
+
+	fmt.Fprintln(w, "flowchart LR")
+	for _, node := range g.Nodes {
+		fmt.Fprintf(w, "  %s%s:::%s\n", node.ID, mermaidShape(node.Kind, mermaidQuote(node.Label)), node.Kind)
+	}
+	for _, edge := range g.Edges {
+		if edge.Label != "" {
+			fmt.Fprintf(w, "  %s -->|%s| %s\n", edge.From, mermaidQuote(edge.Label), edge.To)
+		} else {
+			fmt.Fprintf(w, "  %s --> %s\n", edge.From, edge.To)
+		}
+	}
+	for _, class := range mermaidClasses {
+		fmt.Fprintf(w, "  classDef %s %s\n", class.kind, class.style)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write Mermaid graph: %w", err)
+	}
+
+	return nil
+}
+
+// mermaidShape wraps a quoted label in the node shape used for kind
+func mermaidShape(kind, label string) string {
+	switch kind {
+	case graphRoot:
+		return "[" + label + "]"
+	case graphProvider:
+		return "{{" + label + "}}"
+	default:
+		return "(" + label + ")"
+	}
+}
+
+// mermaidQuote returns value as a quoted Mermaid label. Quotes are written as entity
+// codes and line breaks as <br/>.
+func mermaidQuote(value string) string {
+	value = strings.ReplaceAll(value, `"`, "#quot;")
+	value = strings.ReplaceAll(value, "\r", "")
+	value = strings.ReplaceAll(value, "\n", "<br/>")
+	return `"` + value + `"`
+}
@@ -0,0 +1,68 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportMermaid(t *testing.T) {
+	var buffer strings.Builder
+	if err := Mermaid(graphSBOM, &buffer); err != nil {
+		t.Fatalf("Mermaid() = %v, want nil", err)
+	}
+	output := buffer.String()
+
+	if !strings.HasPrefix(output, "flowchart LR\n") {
+		t.Errorf("Mermaid output does not start with a flowchart:\n%s", output)
+	}
+
+	expected := []string{
+		`  root0["/project"]:::root`,
+		`  module0("network<br/>./modules/network"):::local`,
+		`  module1("vpc<br/>terraform-aws-modules/vpc/aws<br/>5.1.2"):::registry`,
+		`  provider0{{"aws<br/>registry.terraform.io/hashicorp/aws<br/>5.31.0"}}:::provider`,
+		"  root0 --> module0\n",
+		`  module0 -->|"~> 5.0"| module1`,
+		`  module1 -->|">= 4.0"| provider0`,
+		"  classDef registry ",
+		"  classDef vcs ",
+	}
+	for _, want := range expected {
+		if !strings.Contains(output, want) {
+			t.Errorf("Mermaid output missing %q:\n%s", want, output)
+		}
+	}
+}
+
+func TestExportMermaidEscaping(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "weird", Source: `./a "quoted" path`, Version: `">= 1.0"`, Filename: "/project/main.tf", Root: "/project", Path: "module.weird", Depth: 1},
+		},
+	}
+
+	var buffer strings.Builder
+	if err := Mermaid(testSBOM, &buffer); err != nil {
+		t.Fatalf("Mermaid() = %v, want nil", err)
+	}
+	output := buffer.String()
+
+	if !strings.Contains(output, `("weird<br/>./a #quot;quoted#quot; path")`) {
+		t.Errorf("Mermaid output does not escape the node label:\n%s", output)
+	}
+	if !strings.Contains(output, `-->|"#quot;>= 1.0#quot;"|`) {
+		t.Errorf("Mermaid output does not escape the edge label:\n%s", output)
+	}
+}
+
+func TestExportMermaidErrors(t *testing.T) {
+	err := Mermaid(graphSBOM, &failingWriter{})
+	if err == nil {
+		t.Fatal("Mermaid() = nil, want error for failing writer")
+	}
+	if !strings.Contains(err.Error(), "failed to write Mermaid graph") {
+		t.Errorf("error message = %v, want 'failed to write Mermaid graph'", err.Error())
+	}
+}
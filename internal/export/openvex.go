@@ -0,0 +1,124 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// openVEXContext is the JSON-LD context of the OpenVEX version emitted
+const openVEXContext = "https://openvex.dev/ns/v0.2.0"
+
+// openVEXDocument is the root of an OpenVEX document
+type openVEXDocument struct {
+	Context    string             `json:"@context"`
+	ID         string             `json:"@id"`
+	Author     string             `json:"author"`
+	Timestamp  string             `json:"timestamp,omitempty"`
+	Version    int                `json:"version"`
+	Tooling    string             `json:"tooling,omitempty"`
+	Statements []openVEXStatement `json:"statements"`
+}
+
+// openVEXStatement records the status of one vulnerability in a set of products
+type openVEXStatement struct {
+	Vulnerability   openVEXVulnerability `json:"vulnerability"`
+	Products        []openVEXProduct     `json:"products"`
+	Status          string               `json:"status"`
+	Justification   string               `json:"justification,omitempty"`
+	ImpactStatement string               `json:"impact_statement,omitempty"`
+	ActionStatement string               `json:"action_statement,omitempty"`
+}
+
+// openVEXVulnerability names a vulnerability and its aliases
+type openVEXVulnerability struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// openVEXProduct identifies a module or provider version
+type openVEXProduct struct {
+	ID          string            `json:"@id"`
+	Identifiers map[string]string `json:"identifiers,omitempty"`
+}
+
+// OpenVEX exports the vulnerabilities recorded in the SBOM as an OpenVEX document.
+// Findings of the same advisory with the same status share one statement listing
+// every affected module and provider version.
+func OpenVEX(s *sbom.SBOM, writer io.Writer) error {
+	id, err := documentUUID(s)
+	if err != nil {
+		return fmt.Errorf("failed to compute OpenVEX document id: %w", err)
+	}
+
+	doc := openVEXDocument{
+		Context:    openVEXContext,
+		ID:         "urn:uuid:" + id,
+		Author:     s.Tool,
+		Timestamp:  s.Generated,
+		Version:    1,
+		Tooling:    s.Tool,
+		Statements: []openVEXStatement{},
+	}
+
+	index := make(map[string]int)
+	for _, finding := range s.Vulnerabilities {
+		statement := newOpenVEXStatement(finding)
+		key := strings.Join([]string{statement.Vulnerability.Name, statement.Status, statement.Justification, statement.ImpactStatement, statement.ActionStatement}, "\x00")
+
+		i, ok := index[key]
+		if !ok {
+			index[key] = len(doc.Statements)
+			doc.Statements = append(doc.Statements, statement)
+			continue
+		}
+		product := statement.Products[0]
+		if !slices.ContainsFunc(doc.Statements[i].Products, func(p openVEXProduct) bool { return p.ID == product.ID }) {
+			doc.Statements[i].Products = append(doc.Statements[i].Products, product)
+		}
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode SBOM as OpenVEX: %w", err)
+	}
+	return nil
+}
+
+// newOpenVEXStatement maps a finding to a statement about its component
+func newOpenVEXStatement(finding sbom.Vulnerability) openVEXStatement {
+	statement := openVEXStatement{
+		Vulnerability: openVEXVulnerability{Name: finding.ID, Aliases: finding.Aliases},
+		Products:      []openVEXProduct{openVEXProductOf(finding)},
+		Status:        sbom.VulnerabilityAffected,
+	}
+
+	if finding.Suppressed() {
+		statement.Status = sbom.VulnerabilityNotAffected
+		statement.Justification = finding.Justification
+		statement.ImpactStatement = finding.Impact
+		return statement
+	}
+
+	// Affected statements must say what to do about the vulnerability
+	if len(finding.FixedVersions) > 0 {
+		statement.ActionStatement = "Upgrade to " + strings.Join(finding.FixedVersions, " or ")
+	} else {
+		statement.ActionStatement = "No fixed version is available"
+	}
+	return statement
+}
+
+// openVEXProductOf identifies the component of a finding by its package URL or, for
+// providers, by source address and version
+func openVEXProductOf(finding sbom.Vulnerability) openVEXProduct {
+	if finding.PURL != "" {
+		return openVEXProduct{ID: finding.PURL, Identifiers: map[string]string{"purl": finding.PURL}}
+	}
+	return openVEXProduct{ID: finding.Component + "@" + finding.Version}
+}
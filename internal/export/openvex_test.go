@@ -0,0 +1,80 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// vexSBOM has an affected module called twice, an affected provider and a suppressed finding
+var vexSBOM = &sbom.SBOM{
+	Version:   "1.0",
+	Generated: "2024-01-01T00:00:00Z",
+	Tool:      "terraform-sbom",
+	Vulnerabilities: []sbom.Vulnerability{
+		{ID: "TF-1", Aliases: []string{"CVE-2024-1"}, ComponentType: "module", Component: "logs", PURL: "pkg:terraform/ns/bucket@3.0.1?provider=aws", Version: "3.0.1", FixedVersions: []string{"3.2.0"}, Status: sbom.VulnerabilityAffected},
+		{ID: "TF-1", Aliases: []string{"CVE-2024-1"}, ComponentType: "module", Component: "audit", PURL: "pkg:terraform/ns/bucket@3.0.1?provider=aws", Version: "3.0.1", FixedVersions: []string{"3.2.0"}, Status: sbom.VulnerabilityAffected},
+		{ID: "GHSA-2", ComponentType: "provider", Component: "registry.terraform.io/example/xyz", Version: "2.3.1", Status: sbom.VulnerabilityAffected},
+		{ID: "GHSA-3", ComponentType: "provider", Component: "registry.terraform.io/example/xyz", Version: "2.3.1", Status: sbom.VulnerabilityNotAffected, Justification: "vulnerable_code_not_in_execute_path", Impact: "Not used"},
+	},
+}
+
+func TestExportOpenVEX(t *testing.T) {
+	var buffer strings.Builder
+	if err := OpenVEX(vexSBOM, &buffer); err != nil {
+		t.Fatalf("OpenVEX() = %v, want nil", err)
+	}
+
+	var doc openVEXDocument
+	if err := json.Unmarshal([]byte(buffer.String()), &doc); err != nil {
+		t.Fatalf("failed to parse OpenVEX output: %v", err)
+	}
+
+	if doc.Context != openVEXContext || !strings.HasPrefix(doc.ID, "urn:uuid:") {
+		t.Errorf("document = %s %s, want the OpenVEX context and a UUID", doc.Context, doc.ID)
+	}
+	if doc.Author != "terraform-sbom" || doc.Timestamp != "2024-01-01T00:00:00Z" || doc.Version != 1 {
+		t.Errorf("document = %+v, want the SBOM's tool and timestamp", doc)
+	}
+
+	// Both calls of the same module version share one statement
+	if len(doc.Statements) != 3 {
+		t.Fatalf("len(Statements) = %v, want 3", len(doc.Statements))
+	}
+
+	module := doc.Statements[0]
+	if module.Vulnerability.Name != "TF-1" || len(module.Vulnerability.Aliases) != 1 || module.Status != "affected" {
+		t.Errorf("Statements[0] = %+v, want TF-1 affected", module)
+	}
+	if len(module.Products) != 1 || module.Products[0].ID != "pkg:terraform/ns/bucket@3.0.1?provider=aws" || module.Products[0].Identifiers["purl"] != module.Products[0].ID {
+		t.Errorf("Statements[0].Products = %+v, want the module purl once", module.Products)
+	}
+	if module.ActionStatement != "Upgrade to 3.2.0" {
+		t.Errorf("Statements[0].ActionStatement = %q, want 'Upgrade to 3.2.0'", module.ActionStatement)
+	}
+
+	provider := doc.Statements[1]
+	if len(provider.Products) != 1 || provider.Products[0].ID != "registry.terraform.io/example/xyz@2.3.1" {
+		t.Errorf("Statements[1].Products = %+v, want the provider source and version", provider.Products)
+	}
+	if provider.ActionStatement != "No fixed version is available" {
+		t.Errorf("Statements[1].ActionStatement = %q, want 'No fixed version is available'", provider.ActionStatement)
+	}
+
+	suppressed := doc.Statements[2]
+	if suppressed.Status != "not_affected" || suppressed.Justification != "vulnerable_code_not_in_execute_path" || suppressed.ImpactStatement != "Not used" || suppressed.ActionStatement != "" {
+		t.Errorf("Statements[2] = %+v, want not_affected with justification and impact", suppressed)
+	}
+}
+
+func TestExportOpenVEXWithoutFindings(t *testing.T) {
+	var buffer strings.Builder
+	if err := OpenVEX(&sbom.SBOM{Tool: "terraform-sbom"}, &buffer); err != nil {
+		t.Fatalf("OpenVEX() = %v, want nil", err)
+	}
+	if !strings.Contains(buffer.String(), `"statements": []`) {
+		t.Errorf("OpenVEX output does not have an empty statements list:\n%s", buffer.String())
+	}
+}
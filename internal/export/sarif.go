@@ -0,0 +1,298 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/policy"
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// sarifVersion and sarifSchema identify the SARIF version emitted
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifInformationURI points at the tool's documentation
+const sarifInformationURI = "https://github.com/rodmhgl/tf-sbom"
+
+// sarifUnpinnedRule reports remote module calls that do not select a fixed version
+const sarifUnpinnedRule = "unpinned-module"
+
+// SARIF result levels
+const (
+	sarifError   = "error"
+	sarifWarning = "warning"
+	sarifNote    = "note"
+)
+
+// sarifLog is the root of a SARIF log file
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifRun holds the results of one run of the tool
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+// sarifTool describes the tool and the rules it reports
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+// sarifDriver is the tool component that produced the results
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri,omitempty"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+// sarifRule describes a policy rule, the unpinned module check or an advisory
+type sarifRule struct {
+	ID                   string              `json:"id"`
+	ShortDescription     sarifMessage        `json:"shortDescription"`
+	HelpURI              string              `json:"helpUri,omitempty"`
+	DefaultConfiguration *sarifConfiguration `json:"defaultConfiguration,omitempty"`
+	Properties           *sarifProperties    `json:"properties,omitempty"`
+}
+
+// sarifConfiguration holds the default level of a rule
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+// sarifProperties holds the tags that classify a rule
+type sarifProperties struct {
+	Tags []string `json:"tags,omitempty"`
+}
+
+// sarifMessage is a plain text message
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+// sarifResult is a single finding
+type sarifResult struct {
+	RuleID       string             `json:"ruleId"`
+	RuleIndex    int                `json:"ruleIndex"`
+	Level        string             `json:"level"`
+	Message      sarifMessage       `json:"message"`
+	Locations    []sarifLocation    `json:"locations,omitempty"`
+	Suppressions []sarifSuppression `json:"suppressions,omitempty"`
+}
+
+// sarifLocation is where a result was found
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+// sarifPhysicalLocation is a region of a file
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+// sarifArtifactLocation is a file, relative to the source root unless it is a file URI
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// sarifRegion is the line a result starts at
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifSuppression records that a result was triaged, such as by a VEX statement
+type sarifSuppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// sarifRules collects rules in the order results first use them
+type sarifRules struct {
+	rules []sarifRule
+	index map[string]int
+}
+
+// add returns the index of a rule, adding it when it is not yet known
+func (r *sarifRules) add(rule sarifRule) int {
+	if i, ok := r.index[rule.ID]; ok {
+		return i
+	}
+	r.index[rule.ID] = len(r.rules)
+	r.rules = append(r.rules, rule)
+	return len(r.rules) - 1
+}
+
+// SARIF exports the policy violations, unpinned remote module calls and
+// vulnerabilities recorded in the SBOM as a SARIF 2.1.0 log, locating each result at
+// the module block or provider requirement it concerns
+func SARIF(s *sbom.SBOM, writer io.Writer) error {
+	rules := &sarifRules{index: make(map[string]int)}
+	results := []sarifResult{}
+
+	for _, violation := range s.PolicyViolations {
+		index := rules.add(sarifRule{
+			ID:                   violation.Rule,
+			ShortDescription:     sarifMessage{Text: policy.RuleDescriptions[violation.Rule]},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifError},
+			Properties:           &sarifProperties{Tags: []string{"policy"}},
+		})
+		results = append(results, sarifResult{
+			RuleID:    violation.Rule,
+			RuleIndex: index,
+			Level:     sarifError,
+			Message:   sarifMessage{Text: fmt.Sprintf("Module %q: %s", violation.Module, violation.Message)},
+			Locations: sarifLocations(violation.FilePath, violation.Filename, violation.Line, violation.Column),
+		})
+	}
+
+	for _, module := range s.Modules {
+		reason, unpinned := unpinnedReason(module)
+		if !unpinned {
+			continue
+		}
+		index := rules.add(sarifRule{
+			ID:                   sarifUnpinnedRule,
+			ShortDescription:     sarifMessage{Text: "Remote module calls should select a fixed version"},
+			DefaultConfiguration: &sarifConfiguration{Level: sarifWarning},
+			Properties:           &sarifProperties{Tags: []string{"supply-chain"}},
+		})
+		results = append(results, sarifResult{
+			RuleID:    sarifUnpinnedRule,
+			RuleIndex: index,
+			Level:     sarifWarning,
+			Message:   sarifMessage{Text: fmt.Sprintf("Module %q: %s", module.Name, reason)},
+			Locations: sarifLocations(module.FilePath, module.Filename, module.StartLine(), module.Column),
+		})
+	}
+
+	for _, finding := range s.Vulnerabilities {
+		level := sarifSeverityLevel(finding.Severity)
+		rule := sarifRule{
+			ID:                   finding.ID,
+			ShortDescription:     sarifMessage{Text: finding.ID},
+			DefaultConfiguration: &sarifConfiguration{Level: level},
+			Properties:           &sarifProperties{Tags: []string{"security", "vulnerability"}},
+		}
+		if finding.Summary != "" {
+			rule.ShortDescription.Text = finding.Summary
+		}
+		if len(finding.References) > 0 {
+			rule.HelpURI = finding.References[0]
+		}
+		index := rules.add(rule)
+
+		message := fmt.Sprintf("%s %s %s is affected by %s", finding.ComponentType, finding.Component, finding.Version, finding.ID)
+		if len(finding.FixedVersions) > 0 {
+			message += "; fixed in " + strings.Join(finding.FixedVersions, ", ")
+		}
+		result := sarifResult{
+			RuleID:    finding.ID,
+			RuleIndex: index,
+			Level:     level,
+			Message:   sarifMessage{Text: message},
+			Locations: sarifLocations(finding.FilePath, finding.Filename, finding.StartLine(), finding.Column),
+		}
+		if finding.Suppressed() {
+			justification := strings.TrimSpace(finding.Justification + " " + finding.Impact)
+			result.Suppressions = []sarifSuppression{{Kind: "external", Justification: justification}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           s.Tool,
+				InformationURI: sarifInformationURI,
+				Rules:          append([]sarifRule{}, rules.rules...),
+			}},
+			Results: results,
+		}},
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return fmt.Errorf("failed to encode SBOM as SARIF: %w", err)
+	}
+	return nil
+}
+
+// sarifLocations locates a result at a line and column of a file. The path relative to
+// the scan root is preferred; otherwise relative filenames are resolved against the
+// source root and absolute ones become file URIs.
+func sarifLocations(filePath, filename string, line, column int) []sarifLocation {
+	if filePath != "" {
+		filename = filePath
+	}
+	if filename == "" {
+		return nil
+	}
+
+	artifact := sarifArtifactLocation{URI: filepath.ToSlash(filename), URIBaseID: "%SRCROOT%"}
+	if filepath.IsAbs(filename) {
+		artifact = sarifArtifactLocation{URI: "file://" + filepath.ToSlash(filename)}
+	}
+
+	location := sarifLocation{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: artifact}}
+	if line > 0 {
+		location.PhysicalLocation.Region = &sarifRegion{StartLine: line, StartColumn: column}
+	}
+	return []sarifLocation{location}
+}
+
+// sarifSeverityLevel maps an advisory severity rating to a result level. Scores that
+// are not ratings, such as CVSS vectors, are reported as warnings.
+func sarifSeverityLevel(severity string) string {
+	switch strings.ToUpper(severity) {
+	case "CRITICAL", "HIGH":
+		return sarifError
+	case "LOW":
+		return sarifNote
+	default:
+		return sarifWarning
+	}
+}
+
+// unpinnedReason explains why a remote module call does not select a fixed version:
+// a registry module without an exact version, or a version control source without a
+// tag or commit ref. Archive sources have no version to pin.
+func unpinnedReason(module sbom.ModuleInfo) (string, bool) {
+	constraint := module.Version
+	if module.VersionConstraint != "" {
+		constraint = module.VersionConstraint
+	}
+
+	source := moduleSource(module)
+	switch source.Kind {
+	case sbom.SourceRegistry:
+		if constraint == "" {
+			return "registry module has no version", true
+		}
+		if _, ok := sbom.ExactVersion(constraint); !ok {
+			return fmt.Sprintf("version %q is not an exact version", constraint), true
+		}
+	case sbom.SourceGitHub, sbom.SourceBitbucket, sbom.SourceGit, sbom.SourceMercurial:
+		if source.Ref == "" {
+			return "source has no ref", true
+		}
+		if !sbom.IsPinnedRef(source.Ref) {
+			return fmt.Sprintf("ref %q is not a tag or full commit SHA", source.Ref), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,185 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// sarifSBOM has a policy violation, an unpinned and a pinned module, and an affected
+// and a suppressed vulnerability
+var sarifSBOM = &sbom.SBOM{
+	Tool: "terraform-sbom",
+	Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "~> 5.0", Location: "Module call at main.tf:3", Filename: "main.tf"},
+		{Name: "app", Source: "git::https://example.com/app.git?ref=v1.0.0", Location: "Module call at main.tf:8", Filename: "main.tf"},
+		{Name: "net", Source: "./modules/net", Location: "Module call at main.tf:12", Filename: "main.tf"},
+	},
+	PolicyViolations: []sbom.PolicyViolation{
+		{Rule: "allowed-host", Module: "app", Source: "git::https://example.com/app.git?ref=v1.0.0", Filename: "main.tf", Line: 8, Message: `host "example.com" is not allowed`},
+	},
+	Vulnerabilities: []sbom.Vulnerability{
+		{ID: "GHSA-1", Summary: "Credentials logged", Severity: "HIGH", ComponentType: "provider", Component: "registry.terraform.io/example/xyz", Version: "2.3.1", FixedVersions: []string{"2.3.2"}, References: []string{"https://example.com/GHSA-1"}, Location: "Provider requirement at /project/versions.tf:4", Filename: "/project/versions.tf", Status: sbom.VulnerabilityAffected},
+		{ID: "GHSA-1", Summary: "Credentials logged", Severity: "HIGH", ComponentType: "provider", Component: "registry.terraform.io/example/xyz", Version: "2.3.1", Status: sbom.VulnerabilityNotAffected, Justification: "vulnerable_code_not_in_execute_path"},
+	},
+}
+
+func TestExportSARIF(t *testing.T) {
+	var buffer strings.Builder
+	if err := SARIF(sarifSBOM, &buffer); err != nil {
+		t.Fatalf("SARIF() = %v, want nil", err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal([]byte(buffer.String()), &log); err != nil {
+		t.Fatalf("failed to parse SARIF output: %v", err)
+	}
+	if log.Version != "2.1.0" || log.Schema != sarifSchema || len(log.Runs) != 1 {
+		t.Fatalf("log = %s %s with %d runs, want one SARIF 2.1.0 run", log.Version, log.Schema, len(log.Runs))
+	}
+
+	run := log.Runs[0]
+	if run.Tool.Driver.Name != "terraform-sbom" {
+		t.Errorf("Driver.Name = %q, want terraform-sbom", run.Tool.Driver.Name)
+	}
+
+	// Rules are listed once, in the order results first use them
+	expectedRules := []string{"allowed-host", sarifUnpinnedRule, "GHSA-1"}
+	if len(run.Tool.Driver.Rules) != len(expectedRules) {
+		t.Fatalf("len(Rules) = %v, want %v", len(run.Tool.Driver.Rules), len(expectedRules))
+	}
+	for i, id := range expectedRules {
+		if run.Tool.Driver.Rules[i].ID != id {
+			t.Errorf("Rules[%d].ID = %q, want %q", i, run.Tool.Driver.Rules[i].ID, id)
+		}
+	}
+	if rule := run.Tool.Driver.Rules[2]; rule.ShortDescription.Text != "Credentials logged" || rule.HelpURI != "https://example.com/GHSA-1" {
+		t.Errorf("Rules[2] = %+v, want the advisory summary and reference", rule)
+	}
+
+	expected := []struct {
+		ruleID string
+		index  int
+		level  string
+		uri    string
+		base   string
+		line   int
+	}{
+		{"allowed-host", 0, "error", "main.tf", "%SRCROOT%", 8},
+		{sarifUnpinnedRule, 1, "warning", "main.tf", "%SRCROOT%", 3},
+		{"GHSA-1", 2, "error", "file:///project/versions.tf", "", 4},
+		{"GHSA-1", 2, "error", "", "", 0},
+	}
+	if len(run.Results) != len(expected) {
+		t.Fatalf("len(Results) = %v, want %v", len(run.Results), len(expected))
+	}
+	for i, want := range expected {
+		result := run.Results[i]
+		if result.RuleID != want.ruleID || result.RuleIndex != want.index || result.Level != want.level {
+			t.Errorf("Results[%d] = %s %d %s, want %+v", i, result.RuleID, result.RuleIndex, result.Level, want)
+		}
+		if want.uri == "" {
+			if len(result.Locations) != 0 {
+				t.Errorf("Results[%d].Locations = %+v, want none", i, result.Locations)
+			}
+			continue
+		}
+		if len(result.Locations) != 1 {
+			t.Fatalf("Results[%d].Locations = %+v, want one", i, result.Locations)
+		}
+		location := result.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != want.uri || location.ArtifactLocation.URIBaseID != want.base || location.Region == nil || location.Region.StartLine != want.line {
+			t.Errorf("Results[%d] at %+v line %+v, want %s:%d", i, location.ArtifactLocation, location.Region, want.uri, want.line)
+		}
+	}
+
+	if message := run.Results[0].Message.Text; message != `Module "app": host "example.com" is not allowed` {
+		t.Errorf("Results[0].Message = %q", message)
+	}
+	if message := run.Results[2].Message.Text; message != "provider registry.terraform.io/example/xyz 2.3.1 is affected by GHSA-1; fixed in 2.3.2" {
+		t.Errorf("Results[2].Message = %q", message)
+	}
+	if len(run.Results[2].Suppressions) != 0 {
+		t.Errorf("Results[2].Suppressions = %+v, want none", run.Results[2].Suppressions)
+	}
+	if suppressions := run.Results[3].Suppressions; len(suppressions) != 1 || suppressions[0].Kind != "external" || suppressions[0].Justification != "vulnerable_code_not_in_execute_path" {
+		t.Errorf("Results[3].Suppressions = %+v, want an external suppression", suppressions)
+	}
+}
+
+func TestExportSARIFWithoutFindings(t *testing.T) {
+	var buffer strings.Builder
+	if err := SARIF(&sbom.SBOM{Tool: "terraform-sbom"}, &buffer); err != nil {
+		t.Fatalf("SARIF() = %v, want nil", err)
+	}
+	output := buffer.String()
+	if !strings.Contains(output, `"results": []`) || !strings.Contains(output, `"rules": []`) {
+		t.Errorf("SARIF output does not have empty rules and results:\n%s", output)
+	}
+}
+
+func TestUnpinnedReason(t *testing.T) {
+	tests := []struct {
+		name     string
+		module   sbom.ModuleInfo
+		unpinned bool
+	}{
+		{"registry without version", sbom.ModuleInfo{Source: "terraform-aws-modules/vpc/aws"}, true},
+		{"registry with range", sbom.ModuleInfo{Source: "terraform-aws-modules/vpc/aws", Version: ">= 5.0"}, true},
+		{"registry with exact version", sbom.ModuleInfo{Source: "terraform-aws-modules/vpc/aws", Version: "5.1.0"}, false},
+		{"resolved registry keeps its declared range", sbom.ModuleInfo{Source: "terraform-aws-modules/vpc/aws", Version: "5.1.2", VersionConstraint: "~> 5.0"}, true},
+		{"git without ref", sbom.ModuleInfo{Source: "git::https://example.com/app.git"}, true},
+		{"git branch", sbom.ModuleInfo{Source: "github.com/org/repo?ref=main"}, true},
+		{"git tag", sbom.ModuleInfo{Source: "github.com/org/repo?ref=v1.2.0"}, false},
+		{"archive", sbom.ModuleInfo{Source: "https://example.com/module.zip"}, false},
+		{"local", sbom.ModuleInfo{Source: "./modules/vpc"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, unpinned := unpinnedReason(tt.module)
+			if unpinned != tt.unpinned {
+				t.Errorf("unpinnedReason() = %q, %v, want %v", reason, unpinned, tt.unpinned)
+			}
+		})
+	}
+}
+
+func TestSARIFLocations(t *testing.T) {
+	tests := []struct {
+		name     string
+		filePath string
+		filename string
+		line     int
+		column   int
+		want     string
+	}{
+		{"repository path preferred", "modules/vpc/main.tf", "/runner/work/repo/modules/vpc/main.tf", 12, 3, "%SRCROOT%/modules/vpc/main.tf:12:3"},
+		{"relative filename", "", "main.tf", 4, 0, "%SRCROOT%/main.tf:4:0"},
+		{"absolute filename", "", "/project/main.tf", 4, 1, "file:///project/main.tf:4:1"},
+		{"no line", "main.tf", "", 0, 0, "%SRCROOT%/main.tf"},
+		{"no file", "", "", 4, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			for _, location := range sarifLocations(tt.filePath, tt.filename, tt.line, tt.column) {
+				artifact := location.PhysicalLocation.ArtifactLocation
+				got = artifact.URI
+				if artifact.URIBaseID != "" {
+					got = artifact.URIBaseID + "/" + got
+				}
+				if region := location.PhysicalLocation.Region; region != nil {
+					got += fmt.Sprintf(":%d:%d", region.StartLine, region.StartColumn)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("sarifLocations() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,274 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// spdxVersion is the SPDX specification version emitted by the SPDX 2.x exporters
+const spdxVersion = "SPDX-2.3"
+
+// spdxRootID is the SPDX identifier of the package representing the scanned configuration
+const spdxRootID = "SPDXRef-RootConfiguration"
+
+// spdxNoAssertion is used where SPDX requires a value that cannot be determined
+const spdxNoAssertion = "NOASSERTION"
+
+// spdxDocument is the root of an SPDX 2.3 document
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+// spdxCreationInfo records when and by which tool the document was created
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+// spdxPackage represents a single SPDX package
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	Comment          string            `json:"comment,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+// spdxExternalRef links a package to an external identifier such as a purl
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+// spdxRelationship describes a relationship between two SPDX elements
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+// SPDXJSON exports SBOM as an SPDX 2.3 JSON document to the provided writer
+func SPDXJSON(s *sbom.SBOM, writer io.Writer) error {
+	doc, err := newSPDXDocument(s)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode SBOM as SPDX JSON: %w", err)
+	}
+
+	return nil
+}
+
+// newSPDXDocument converts an SBOM into an SPDX 2.3 document. The scanned configuration
+// is modelled as a root package that DEPENDS_ON one package per module call.
+func newSPDXDocument(s *sbom.SBOM) (*spdxDocument, error) {
+	id, err := documentUUID(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SPDX document namespace: %w", err)
+	}
+
+	tool := s.Tool
+	if tool == "" {
+		tool = "terraform-sbom"
+	}
+	creator := "Tool: " + tool
+	if s.Version != "" {
+		creator += "-" + s.Version
+	}
+
+	doc := &spdxDocument{
+		SPDXVersion:       spdxVersion,
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              "terraform-configuration",
+		DocumentNamespace: "https://spdx.org/spdxdocs/" + tool + "-" + id,
+		CreationInfo: spdxCreationInfo{
+			Created:  spdxTimestamp(s.Generated),
+			Creators: []string{creator},
+		},
+		Packages: []spdxPackage{
+			{
+				SPDXID:           spdxRootID,
+				Name:             "terraform-configuration",
+				DownloadLocation: spdxNoAssertion,
+				FilesAnalyzed:    false,
+				Comment:          "Terraform configuration scanned by " + tool,
+			},
+		},
+		Relationships: []spdxRelationship{
+			{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxRootID},
+		},
+	}
+
+	// Identifiers are allocated up front since a parent may sort after its children
+	ids := newSPDXIDAllocator()
+	moduleIDs := make([]string, len(s.Modules))
+	for i, module := range s.Modules {
+		moduleIDs[i] = ids.allocate("Module-" + module.Name)
+	}
+
+	// Nested module calls depend from the module that makes them rather than the root
+	parents := moduleParents(s.Modules)
+	for i, module := range s.Modules {
+		from := spdxRootID
+		if parents[i] >= 0 {
+			from = moduleIDs[parents[i]]
+		}
+
+		doc.Packages = append(doc.Packages, newSPDXPackage(module, moduleIDs[i]))
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      from,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: moduleIDs[i],
+		})
+	}
+	for _, provider := range s.Providers {
+		pkg := newSPDXProviderPackage(provider, ids.allocate("Provider-"+provider.Name))
+		doc.Packages = append(doc.Packages, pkg)
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      spdxRootID,
+			RelationshipType:   "DEPENDS_ON",
+			RelatedSPDXElement: pkg.SPDXID,
+		})
+	}
+
+	return doc, nil
+}
+
+// newSPDXPackage maps a Terraform module call to an SPDX package
+func newSPDXPackage(module sbom.ModuleInfo, spdxID string) spdxPackage {
+	pkg := spdxPackage{
+		SPDXID:           spdxID,
+		Name:             module.Name,
+		VersionInfo:      module.Version,
+		DownloadLocation: spdxDownloadLocation(module),
+		FilesAnalyzed:    false,
+		SourceInfo:       module.Location,
+		Comment:          "Terraform module source: " + module.Source,
+	}
+
+	if module.PURL != "" {
+		pkg.ExternalRefs = []spdxExternalRef{
+			{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: module.PURL},
+		}
+	}
+
+	return pkg
+}
+
+// newSPDXProviderPackage maps a required provider to an SPDX package
+func newSPDXProviderPackage(provider sbom.ProviderInfo, spdxID string) spdxPackage {
+	return spdxPackage{
+		SPDXID:           spdxID,
+		Name:             provider.Name,
+		VersionInfo:      provider.EffectiveVersion(),
+		DownloadLocation: providerDownloadLocation(provider),
+		FilesAnalyzed:    false,
+		SourceInfo:       provider.Location,
+		Comment:          "Terraform provider source: " + provider.Source,
+	}
+}
+
+// spdxTimestamp converts an RFC 3339 timestamp into the UTC form required by SPDX
+func spdxTimestamp(generated string) string {
+	t, err := time.Parse(time.RFC3339, generated)
+	if err != nil {
+		return generated
+	}
+	return t.UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// spdxIDInvalidChars matches characters that are not allowed in an SPDX identifier
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]+`)
+
+// spdxIDAllocator hands out SPDX identifiers that are unique within a single document
+type spdxIDAllocator struct {
+	used map[string]bool
+	// next is the next counter to try for each sanitized identifier
+	next map[string]int
+}
+
+// newSPDXIDAllocator creates an empty SPDX identifier allocator
+func newSPDXIDAllocator() *spdxIDAllocator {
+	return &spdxIDAllocator{used: make(map[string]bool), next: make(map[string]int)}
+}
+
+// allocate sanitizes name into a valid "SPDXRef-" identifier, appending the lowest
+// counter that yields an unused identifier when the sanitized form has already been
+// handed out
+func (a *spdxIDAllocator) allocate(name string) string {
+	base := "SPDXRef-" + strings.Trim(spdxIDInvalidChars.ReplaceAllString(name, "-"), "-")
+
+	id := base
+	for count := max(a.next[base], 2); a.used[id]; count++ {
+		id = fmt.Sprintf("%s-%d", base, count)
+		a.next[base] = count + 1
+	}
+	a.used[id] = true
+	return id
+}
+
+// spdxDownloadLocation derives an SPDX download location from a module source address
+func spdxDownloadLocation(module sbom.ModuleInfo) string {
+	source := moduleSource(module)
+
+	switch source.Kind {
+	case sbom.SourceGit, sbom.SourceMercurial:
+		return vcsDownloadLocation(string(source.Kind), source.URL, source.Ref)
+	case sbom.SourceGitHub, sbom.SourceBitbucket:
+		return vcsDownloadLocation("git", source.URL, source.Ref)
+	case sbom.SourceHTTP, sbom.SourceS3, sbom.SourceGCS:
+		if query := source.QueryString(); query != "" {
+			return source.URL + "?" + query
+		}
+		return source.URL
+	case sbom.SourceRegistry:
+		location := fmt.Sprintf("https://%s/v1/modules/%s/%s/%s", source.Host, source.Namespace, source.Name, source.Provider)
+		if version, ok := sbom.ExactVersion(module.Version); ok {
+			location += "/" + version + "/download"
+		}
+		return location
+	}
+
+	return spdxNoAssertion
+}
+
+// providerDownloadLocation points at the registry API entry for a provider
+func providerDownloadLocation(provider sbom.ProviderInfo) string {
+	host, namespace, providerType := provider.SourceParts()
+	if host == "" {
+		return spdxNoAssertion
+	}
+	return fmt.Sprintf("https://%s/v1/providers/%s/%s", host, namespace, providerType)
+}
+
+// vcsDownloadLocation builds an SPDX VCS locator of the form <vcs>+<url>[@<ref>]
+func vcsDownloadLocation(vcs, repoURL, ref string) string {
+	location := vcs + "+" + repoURL
+	if ref != "" {
+		location += "@" + ref
+	}
+	return location
+}
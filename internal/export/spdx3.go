@@ -0,0 +1,239 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// spdx3SpecVersion is the SPDX specification version emitted by the SPDX 3 exporter
+const spdx3SpecVersion = "3.0.1"
+
+// spdx3Context is the JSON-LD context published for the SPDX 3.0 model
+const spdx3Context = "https://spdx.org/rdf/3.0.1/spdx-context.jsonld"
+
+// spdx3CreationInfoID is the blank node shared by every element's creationInfo
+const spdx3CreationInfoID = "_:creationinfo"
+
+// spdx3Document is the root of an SPDX 3.0 JSON-LD serialization
+type spdx3Document struct {
+	Context string `json:"@context"`
+	Graph   []any  `json:"@graph"`
+}
+
+// spdx3CreationInfo records when and by whom the elements were created
+type spdx3CreationInfo struct {
+	Type         string   `json:"type"`
+	ID           string   `json:"@id"`
+	SpecVersion  string   `json:"specVersion"`
+	Created      string   `json:"created"`
+	CreatedBy    []string `json:"createdBy"`
+	CreatedUsing []string `json:"createdUsing,omitempty"`
+}
+
+// spdx3Element holds the properties shared by agents, tools and the SPDX document
+type spdx3Element struct {
+	Type               string   `json:"type"`
+	SPDXID             string   `json:"spdxId"`
+	CreationInfo       string   `json:"creationInfo"`
+	Name               string   `json:"name,omitempty"`
+	Comment            string   `json:"comment,omitempty"`
+	RootElement        []string `json:"rootElement,omitempty"`
+	Element            []string `json:"element,omitempty"`
+	ProfileConformance []string `json:"profileConformance,omitempty"`
+}
+
+// spdx3Package is a software_Package element
+type spdx3Package struct {
+	Type             string `json:"type"`
+	SPDXID           string `json:"spdxId"`
+	CreationInfo     string `json:"creationInfo"`
+	Name             string `json:"name"`
+	Comment          string `json:"comment,omitempty"`
+	PackageVersion   string `json:"software_packageVersion,omitempty"`
+	DownloadLocation string `json:"software_downloadLocation,omitempty"`
+	PackageURL       string `json:"software_packageUrl,omitempty"`
+	PrimaryPurpose   string `json:"software_primaryPurpose,omitempty"`
+	SourceInfo       string `json:"software_sourceInfo,omitempty"`
+}
+
+// spdx3Relationship is a Relationship element between two or more elements
+type spdx3Relationship struct {
+	Type             string   `json:"type"`
+	SPDXID           string   `json:"spdxId"`
+	CreationInfo     string   `json:"creationInfo"`
+	From             string   `json:"from"`
+	RelationshipType string   `json:"relationshipType"`
+	To               []string `json:"to"`
+	Comment          string   `json:"comment,omitempty"`
+}
+
+// SPDX3 exports SBOM as an SPDX 3.0 JSON-LD document to the provided writer
+func SPDX3(s *sbom.SBOM, writer io.Writer) error {
+	doc, err := newSPDX3Document(s)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ") // Pretty print with 2-space indentation
+
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode SBOM as SPDX 3.0 JSON-LD: %w", err)
+	}
+
+	return nil
+}
+
+// newSPDX3Document converts an SBOM into an SPDX 3.0 graph. Like the SPDX 2.3
+// exporter, the scanned configuration is a root package with a dependsOn
+// relationship to every module call.
+func newSPDX3Document(s *sbom.SBOM) (*spdx3Document, error) {
+	id, err := documentUUID(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute SPDX document namespace: %w", err)
+	}
+
+	tool := s.Tool
+	if tool == "" {
+		tool = "terraform-sbom"
+	}
+	namespace := "https://spdx.org/spdxdocs/" + tool + "-" + id + "#"
+	ids := newSPDXIDAllocator()
+
+	agentID := namespace + ids.allocate("Agent-"+tool)
+	toolID := namespace + ids.allocate("Tool-"+tool)
+	documentID := namespace + "SPDXRef-DOCUMENT"
+	rootID := namespace + spdxRootID
+
+	toolName := tool
+	if s.Version != "" {
+		toolName += "-" + s.Version
+	}
+
+	var elements []string
+	graph := []any{
+		spdx3CreationInfo{
+			Type:         "CreationInfo",
+			ID:           spdx3CreationInfoID,
+			SpecVersion:  spdx3SpecVersion,
+			Created:      spdxTimestamp(s.Generated),
+			CreatedBy:    []string{agentID},
+			CreatedUsing: []string{toolID},
+		},
+		spdx3Element{Type: "SoftwareAgent", SPDXID: agentID, CreationInfo: spdx3CreationInfoID, Name: tool},
+		spdx3Element{Type: "Tool", SPDXID: toolID, CreationInfo: spdx3CreationInfoID, Name: toolName},
+		spdx3Package{
+			Type:           "software_Package",
+			SPDXID:         rootID,
+			CreationInfo:   spdx3CreationInfoID,
+			Name:           "terraform-configuration",
+			Comment:        "Terraform configuration scanned by " + tool,
+			PrimaryPurpose: "application",
+		},
+	}
+	elements = append(elements, agentID, toolID, rootID)
+
+	// Identifiers are allocated up front since a parent may sort after its children
+	moduleIDs := make([]string, len(s.Modules))
+	for i, module := range s.Modules {
+		moduleIDs[i] = namespace + ids.allocate("Module-"+module.Name)
+	}
+
+	parents := moduleParents(s.Modules)
+	for i, module := range s.Modules {
+		pkg := newSPDX3Package(module, moduleIDs[i])
+
+		from := rootID
+		if parents[i] >= 0 {
+			from = moduleIDs[parents[i]]
+		}
+
+		rel := spdx3Relationship{
+			Type:             "Relationship",
+			SPDXID:           namespace + ids.allocate("Relationship-"+module.Name),
+			CreationInfo:     spdx3CreationInfoID,
+			From:             from,
+			RelationshipType: "dependsOn",
+			To:               []string{pkg.SPDXID},
+			Comment:          module.Location,
+		}
+		graph = append(graph, pkg, rel)
+		elements = append(elements, pkg.SPDXID, rel.SPDXID)
+	}
+	for _, provider := range s.Providers {
+		pkg := newSPDX3ProviderPackage(provider, namespace+ids.allocate("Provider-"+provider.Name))
+		rel := spdx3Relationship{
+			Type:             "Relationship",
+			SPDXID:           namespace + ids.allocate("Relationship-"+provider.Name),
+			CreationInfo:     spdx3CreationInfoID,
+			From:             rootID,
+			RelationshipType: "dependsOn",
+			To:               []string{pkg.SPDXID},
+			Comment:          provider.Location,
+		}
+		graph = append(graph, pkg, rel)
+		elements = append(elements, pkg.SPDXID, rel.SPDXID)
+	}
+
+	graph = append(graph, spdx3Element{
+		Type:               "SpdxDocument",
+		SPDXID:             documentID,
+		CreationInfo:       spdx3CreationInfoID,
+		Name:               "terraform-configuration",
+		RootElement:        []string{rootID},
+		Element:            elements,
+		ProfileConformance: []string{"core", "software"},
+	})
+
+	return &spdx3Document{Context: spdx3Context, Graph: graph}, nil
+}
+
+// newSPDX3Package maps a Terraform module call to a software_Package element
+func newSPDX3Package(module sbom.ModuleInfo, spdxID string) spdx3Package {
+	purpose := "library"
+	if sbom.IsLocalSource(module.Source) {
+		purpose = "source"
+	}
+
+	pkg := spdx3Package{
+		Type:           "software_Package",
+		SPDXID:         spdxID,
+		CreationInfo:   spdx3CreationInfoID,
+		Name:           module.Name,
+		Comment:        "Terraform module source: " + module.Source,
+		PackageVersion: module.Version,
+		PackageURL:     module.PURL,
+		PrimaryPurpose: purpose,
+		SourceInfo:     module.Location,
+	}
+
+	// SPDX 3 has no NOASSERTION sentinel; unknown locations are simply omitted
+	if location := spdxDownloadLocation(module); location != spdxNoAssertion {
+		pkg.DownloadLocation = location
+	}
+
+	return pkg
+}
+
+// newSPDX3ProviderPackage maps a required provider to a software_Package element
+func newSPDX3ProviderPackage(provider sbom.ProviderInfo, spdxID string) spdx3Package {
+	pkg := spdx3Package{
+		Type:           "software_Package",
+		SPDXID:         spdxID,
+		CreationInfo:   spdx3CreationInfoID,
+		Name:           provider.Name,
+		Comment:        "Terraform provider source: " + provider.Source,
+		PackageVersion: provider.EffectiveVersion(),
+		PrimaryPurpose: "executable",
+		SourceInfo:     provider.Location,
+	}
+
+	if location := providerDownloadLocation(provider); location != spdxNoAssertion {
+		pkg.DownloadLocation = location
+	}
+
+	return pkg
+}
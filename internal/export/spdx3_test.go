@@ -0,0 +1,229 @@
+package export
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportSPDX3Errors(t *testing.T) {
+	t.Run("write error", func(t *testing.T) {
+		testSBOM := &sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "test", Source: "test", Version: "1.0", Location: "test", Filename: "/project/test.tf"},
+			},
+		}
+
+		err := SPDX3(testSBOM, &failingWriter{})
+		if err == nil {
+			t.Error("SPDX3() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to encode SBOM as SPDX 3.0 JSON-LD") {
+			t.Errorf("error message = %v, want 'failed to encode SBOM as SPDX 3.0 JSON-LD'", err.Error())
+		}
+	})
+}
+
+func TestExportSPDX3(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{
+				Name:     "vpc",
+				Source:   "terraform-aws-modules/vpc/aws",
+				Version:  "5.1.0",
+				Location: "Module call at /project/main.tf:10",
+				Filename: "/project/main.tf",
+				PURL:     "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws",
+			},
+			{
+				Name:     "local_module",
+				Source:   "./modules/local",
+				Location: "Module call at /project/main.tf:20",
+				Filename: "/project/main.tf",
+			},
+		},
+	}
+
+	var buffer strings.Builder
+	if err := SPDX3(testSBOM, &buffer); err != nil {
+		t.Fatalf("SPDX3() = %v, want nil", err)
+	}
+
+	var doc struct {
+		Context string           `json:"@context"`
+		Graph   []map[string]any `json:"@graph"`
+	}
+	if err := json.Unmarshal([]byte(buffer.String()), &doc); err != nil {
+		t.Fatalf("failed to parse SPDX 3.0 JSON-LD output: %v", err)
+	}
+
+	byType := make(map[string][]map[string]any)
+	byID := make(map[string]map[string]any)
+	for _, node := range doc.Graph {
+		nodeType, _ := node["type"].(string)
+		byType[nodeType] = append(byType[nodeType], node)
+		if id, ok := node["spdxId"].(string); ok {
+			byID[id] = node
+		}
+	}
+
+	t.Run("context and creation info", func(t *testing.T) {
+		if doc.Context != "https://spdx.org/rdf/3.0.1/spdx-context.jsonld" {
+			t.Errorf("@context = %v, want SPDX 3.0.1 context", doc.Context)
+		}
+		if len(byType["CreationInfo"]) != 1 {
+			t.Fatalf("len(CreationInfo) = %v, want 1", len(byType["CreationInfo"]))
+		}
+		info := byType["CreationInfo"][0]
+		if info["specVersion"] != "3.0.1" {
+			t.Errorf("specVersion = %v, want '3.0.1'", info["specVersion"])
+		}
+		if info["created"] != "2024-01-02T03:04:05Z" {
+			t.Errorf("created = %v, want '2024-01-02T03:04:05Z'", info["created"])
+		}
+		if len(byType["Tool"]) != 1 || byType["Tool"][0]["name"] != "terraform-sbom-1.0" {
+			t.Errorf("Tool elements = %v, want a single terraform-sbom-1.0 tool", byType["Tool"])
+		}
+	})
+
+	t.Run("packages", func(t *testing.T) {
+		packages := byType["software_Package"]
+		if len(packages) != 3 {
+			t.Fatalf("len(software_Package) = %v, want 3", len(packages))
+		}
+
+		vpc := packages[1]
+		if vpc["name"] != "vpc" {
+			t.Errorf("packages[1].name = %v, want 'vpc'", vpc["name"])
+		}
+		if vpc["software_packageVersion"] != "5.1.0" {
+			t.Errorf("vpc.software_packageVersion = %v, want '5.1.0'", vpc["software_packageVersion"])
+		}
+		if vpc["software_packageUrl"] != "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws" {
+			t.Errorf("vpc.software_packageUrl = %v", vpc["software_packageUrl"])
+		}
+		if vpc["software_primaryPurpose"] != "library" {
+			t.Errorf("vpc.software_primaryPurpose = %v, want 'library'", vpc["software_primaryPurpose"])
+		}
+
+		local := packages[2]
+		if _, ok := local["software_downloadLocation"]; ok {
+			t.Error("local module should not have a download location")
+		}
+		if !strings.HasSuffix(local["spdxId"].(string), "#SPDXRef-Module-local-module") {
+			t.Errorf("local.spdxId = %v, want #SPDXRef-Module-local-module suffix", local["spdxId"])
+		}
+	})
+
+	t.Run("relationships", func(t *testing.T) {
+		relationships := byType["Relationship"]
+		if len(relationships) != 2 {
+			t.Fatalf("len(Relationship) = %v, want 2", len(relationships))
+		}
+
+		rootID := byType["software_Package"][0]["spdxId"]
+		for i, rel := range relationships {
+			if rel["relationshipType"] != "dependsOn" {
+				t.Errorf("relationships[%d].relationshipType = %v, want 'dependsOn'", i, rel["relationshipType"])
+			}
+			if rel["from"] != rootID {
+				t.Errorf("relationships[%d].from = %v, want %v", i, rel["from"], rootID)
+			}
+			to, _ := rel["to"].([]any)
+			if len(to) != 1 || byID[to[0].(string)] == nil {
+				t.Errorf("relationships[%d].to = %v, want a single element in the graph", i, rel["to"])
+			}
+		}
+	})
+
+	t.Run("SPDX document", func(t *testing.T) {
+		if len(byType["SpdxDocument"]) != 1 {
+			t.Fatalf("len(SpdxDocument) = %v, want 1", len(byType["SpdxDocument"]))
+		}
+		document := byType["SpdxDocument"][0]
+
+		rootElements, _ := document["rootElement"].([]any)
+		if len(rootElements) != 1 || rootElements[0] != byType["software_Package"][0]["spdxId"] {
+			t.Errorf("rootElement = %v, want the root configuration package", document["rootElement"])
+		}
+
+		// Every element in the graph other than the document itself must be listed
+		elements, _ := document["element"].([]any)
+		if len(elements) != len(byID)-1 {
+			t.Errorf("len(element) = %v, want %v", len(elements), len(byID)-1)
+		}
+		for _, element := range elements {
+			if byID[element.(string)] == nil {
+				t.Errorf("element %v is not present in the graph", element)
+			}
+		}
+	})
+}
+
+func TestSPDX3NestedModuleRelationships(t *testing.T) {
+	doc, err := newSPDX3Document(nestedModulesSBOM)
+	if err != nil {
+		t.Fatalf("newSPDX3Document() = %v, want nil", err)
+	}
+
+	var network, vpc spdx3Package
+	var relationships []spdx3Relationship
+	for _, element := range doc.Graph {
+		switch element := element.(type) {
+		case spdx3Package:
+			switch element.Name {
+			case "network":
+				network = element
+			case "vpc":
+				vpc = element
+			}
+		case spdx3Relationship:
+			relationships = append(relationships, element)
+		}
+	}
+
+	found := false
+	for _, rel := range relationships {
+		if len(rel.To) == 1 && rel.To[0] == vpc.SPDXID {
+			found = true
+			if rel.From != network.SPDXID {
+				t.Errorf("vpc relationship from = %v, want %v", rel.From, network.SPDXID)
+			}
+		}
+	}
+	if !found {
+		t.Error("expected a dependsOn relationship to the vpc package")
+	}
+}
+
+func TestSPDX3ChildBeforeParent(t *testing.T) {
+	doc, err := newSPDX3Document(childFirstModulesSBOM)
+	if err != nil {
+		t.Fatalf("newSPDX3Document() = %v, want nil", err)
+	}
+
+	ids := make(map[string]string)
+	var relationships []spdx3Relationship
+	for _, element := range doc.Graph {
+		switch element := element.(type) {
+		case spdx3Package:
+			ids[element.Name] = element.SPDXID
+		case spdx3Relationship:
+			relationships = append(relationships, element)
+		}
+	}
+
+	for _, rel := range relationships {
+		if rel.From == "" {
+			t.Errorf("relationship %s has no from element", rel.SPDXID)
+		}
+		if len(rel.To) == 1 && rel.To[0] == ids["vpc"] && rel.From != ids["net"] {
+			t.Errorf("vpc relationship from = %v, want %v", rel.From, ids["net"])
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// SPDXTagValue exports SBOM as an SPDX 2.3 tag-value document to the provided writer
+func SPDXTagValue(s *sbom.SBOM, writer io.Writer) error {
+	doc, err := newSPDXDocument(s)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(writer)
+
+	// Document creation information
+	writeTag(w, "SPDXVersion", doc.SPDXVersion)
+	writeTag(w, "DataLicense", doc.DataLicense)
+	writeTag(w, "SPDXID", doc.SPDXID)
+	writeTag(w, "DocumentName", doc.Name)
+	writeTag(w, "DocumentNamespace", doc.DocumentNamespace)
+	for _, creator := range doc.CreationInfo.Creators {
+		writeTag(w, "Creator", creator)
+	}
+	writeTag(w, "Created", doc.CreationInfo.Created)
+
+	for _, pkg := range doc.Packages {
+		fmt.Fprintf(w, "\n##### Package: %s\n\n", singleLine(pkg.Name))
+		writeTag(w, "PackageName", pkg.Name)
+		writeTag(w, "SPDXID", pkg.SPDXID)
+		if pkg.VersionInfo != "" {
+			writeTag(w, "PackageVersion", pkg.VersionInfo)
+		}
+		writeTag(w, "PackageDownloadLocation", pkg.DownloadLocation)
+		writeTag(w, "FilesAnalyzed", fmt.Sprintf("%t", pkg.FilesAnalyzed))
+		if pkg.SourceInfo != "" {
+			writeTextTag(w, "PackageSourceInfo", pkg.SourceInfo)
+		}
+		if pkg.Comment != "" {
+			writeTextTag(w, "PackageComment", pkg.Comment)
+		}
+		for _, ref := range pkg.ExternalRefs {
+			writeTag(w, "ExternalRef", ref.ReferenceCategory+" "+ref.ReferenceType+" "+ref.ReferenceLocator)
+		}
+	}
+
+	fmt.Fprint(w, "\n##### Relationships\n\n")
+	for _, rel := range doc.Relationships {
+		writeTag(w, "Relationship", rel.SPDXElementID+" "+rel.RelationshipType+" "+rel.RelatedSPDXElement)
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write SPDX tag-value document: %w", err)
+	}
+
+	return nil
+}
+
+// writeTag writes a single-line "Tag: value" pair
+func writeTag(w *bufio.Writer, tag, value string) {
+	fmt.Fprintf(w, "%s: %s\n", tag, singleLine(value))
+}
+
+// writeTextTag writes a free-form value wrapped in <text></text> so that it may span
+// multiple lines
+func writeTextTag(w *bufio.Writer, tag, value string) {
+	fmt.Fprintf(w, "%s: <text>%s</text>\n", tag, escapeTagValueText(value))
+}
+
+// singleLine collapses line breaks so that a value cannot spill into the next tag
+func singleLine(value string) string {
+	return strings.Join(strings.Fields(strings.ReplaceAll(value, "\r", "")), " ")
+}
+
+// escapeTagValueText prevents a literal closing tag inside a <text> value from
+// terminating the value early
+func escapeTagValueText(value string) string {
+	return strings.ReplaceAll(value, "</text>", "&lt;/text&gt;")
+}
@@ -0,0 +1,140 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportSPDXTagValueErrors(t *testing.T) {
+	t.Run("write error", func(t *testing.T) {
+		testSBOM := &sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "test", Source: "test", Version: "1.0", Location: "test", Filename: "/project/test.tf"},
+			},
+		}
+
+		err := SPDXTagValue(testSBOM, &failingWriter{})
+		if err == nil {
+			t.Error("SPDXTagValue() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to write SPDX tag-value document") {
+			t.Errorf("error message = %v, want 'failed to write SPDX tag-value document'", err.Error())
+		}
+	})
+}
+
+func TestExportSPDXTagValue(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{
+				Name:     "vpc",
+				Source:   "terraform-aws-modules/vpc/aws",
+				Version:  "5.1.0",
+				Location: "Module call at /project/main.tf:10",
+				Filename: "/project/main.tf",
+				PURL:     "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws",
+			},
+			{
+				Name:     "app.v2-core_module",
+				Source:   "./modules/app",
+				Location: "Module call at /project/odd</text>\nname.tf:4",
+				Filename: "/project/odd</text>\nname.tf",
+			},
+		},
+	}
+
+	var buffer strings.Builder
+	if err := SPDXTagValue(testSBOM, &buffer); err != nil {
+		t.Fatalf("SPDXTagValue() = %v, want nil", err)
+	}
+	output := buffer.String()
+
+	t.Run("document creation information", func(t *testing.T) {
+		expected := []string{
+			"SPDXVersion: SPDX-2.3\n",
+			"DataLicense: CC0-1.0\n",
+			"SPDXID: SPDXRef-DOCUMENT\n",
+			"DocumentName: terraform-configuration\n",
+			"DocumentNamespace: https://spdx.org/spdxdocs/terraform-sbom-",
+			"Creator: Tool: terraform-sbom-1.0\n",
+			"Created: 2024-01-02T03:04:05Z\n",
+		}
+		for _, line := range expected {
+			if !strings.Contains(output, line) {
+				t.Errorf("output should contain %q", line)
+			}
+		}
+		if !strings.HasPrefix(output, "SPDXVersion: SPDX-2.3\n") {
+			t.Error("document should start with SPDXVersion tag")
+		}
+	})
+
+	t.Run("packages", func(t *testing.T) {
+		expected := []string{
+			"PackageName: vpc\nSPDXID: SPDXRef-Module-vpc\nPackageVersion: 5.1.0\n",
+			"PackageDownloadLocation: https://registry.terraform.io/v1/modules/terraform-aws-modules/vpc/aws/5.1.0/download\n",
+			"FilesAnalyzed: false\n",
+			"PackageSourceInfo: <text>Module call at /project/main.tf:10</text>\n",
+			"ExternalRef: PACKAGE-MANAGER purl pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws\n",
+		}
+		for _, line := range expected {
+			if !strings.Contains(output, line) {
+				t.Errorf("output should contain %q", line)
+			}
+		}
+	})
+
+	t.Run("identifier sanitization", func(t *testing.T) {
+		if !strings.Contains(output, "SPDXID: SPDXRef-Module-app.v2-core-module\n") {
+			t.Error("module names should be sanitized to the SPDX identifier character set")
+		}
+		if !strings.Contains(output, "PackageName: app.v2-core_module\n") {
+			t.Error("package name should keep the original module name")
+		}
+	})
+
+	t.Run("multi-line text escaping", func(t *testing.T) {
+		if !strings.Contains(output, "PackageSourceInfo: <text>Module call at /project/odd&lt;/text&gt;\nname.tf:4</text>\n") {
+			t.Error("multi-line text should stay inside a single <text> block with closing tags escaped")
+		}
+		if strings.Count(output, "</text>") != strings.Count(output, "<text>") {
+			t.Error("every <text> block should be closed exactly once")
+		}
+	})
+
+	t.Run("relationships", func(t *testing.T) {
+		expected := []string{
+			"Relationship: SPDXRef-DOCUMENT DESCRIBES SPDXRef-RootConfiguration\n",
+			"Relationship: SPDXRef-RootConfiguration DEPENDS_ON SPDXRef-Module-vpc\n",
+			"Relationship: SPDXRef-RootConfiguration DEPENDS_ON SPDXRef-Module-app.v2-core-module\n",
+		}
+		for _, line := range expected {
+			if !strings.Contains(output, line) {
+				t.Errorf("output should contain %q", line)
+			}
+		}
+	})
+}
+
+func TestSingleLine(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"simple", "simple"},
+		{"two\nlines", "two lines"},
+		{"windows\r\nline", "windows line"},
+		{"  padded  ", "padded"},
+	}
+
+	for _, test := range tests {
+		if result := singleLine(test.input); result != test.expected {
+			t.Errorf("singleLine(%q) = %q, want %q", test.input, result, test.expected)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package export
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestExportSPDXJSONErrors(t *testing.T) {
+	t.Run("write error", func(t *testing.T) {
+		testSBOM := &sbom.SBOM{
+			Modules: []sbom.ModuleInfo{
+				{Name: "test", Source: "test", Version: "1.0", Location: "test", Filename: "/project/test.tf"},
+			},
+		}
+
+		err := SPDXJSON(testSBOM, &failingWriter{})
+		if err == nil {
+			t.Error("SPDXJSON() = nil, want error for failing writer")
+		}
+		if !strings.Contains(err.Error(), "failed to encode SBOM as SPDX JSON") {
+			t.Errorf("error message = %v, want 'failed to encode SBOM as SPDX JSON'", err.Error())
+		}
+	})
+}
+
+func TestExportSPDXJSON(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T05:04:05+02:00",
+		Tool:      "terraform-sbom",
+		Modules: []sbom.ModuleInfo{
+			{
+				Name:     "vpc",
+				Source:   "terraform-aws-modules/vpc/aws",
+				Version:  "5.1.0",
+				Location: "Module call at /project/main.tf:10",
+				Filename: "/project/main.tf",
+				PURL:     "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws",
+			},
+			{
+				Name:     "network_core",
+				Source:   "git::https://github.com/example/network.git//modules/core?ref=v1.2.0",
+				Location: "Module call at /project/main.tf:20",
+				Filename: "/project/main.tf",
+			},
+			{
+				Name:     "network_core",
+				Source:   "./modules/core",
+				Location: "Module call at /project/env/main.tf:3",
+				Filename: "/project/env/main.tf",
+			},
+		},
+	}
+
+	t.Run("document structure", func(t *testing.T) {
+		var buffer strings.Builder
+		if err := SPDXJSON(testSBOM, &buffer); err != nil {
+			t.Fatalf("SPDXJSON() = %v, want nil", err)
+		}
+
+		var doc spdxDocument
+		if err := json.Unmarshal([]byte(buffer.String()), &doc); err != nil {
+			t.Fatalf("failed to parse SPDX JSON output: %v", err)
+		}
+
+		if doc.SPDXVersion != "SPDX-2.3" {
+			t.Errorf("spdxVersion = %v, want 'SPDX-2.3'", doc.SPDXVersion)
+		}
+		if doc.DataLicense != "CC0-1.0" {
+			t.Errorf("dataLicense = %v, want 'CC0-1.0'", doc.DataLicense)
+		}
+		if doc.SPDXID != "SPDXRef-DOCUMENT" {
+			t.Errorf("SPDXID = %v, want 'SPDXRef-DOCUMENT'", doc.SPDXID)
+		}
+		if !strings.HasPrefix(doc.DocumentNamespace, "https://spdx.org/spdxdocs/terraform-sbom-") {
+			t.Errorf("documentNamespace = %v, want https://spdx.org/spdxdocs/terraform-sbom- prefix", doc.DocumentNamespace)
+		}
+		if doc.CreationInfo.Created != "2024-01-02T03:04:05Z" {
+			t.Errorf("creationInfo.created = %v, want '2024-01-02T03:04:05Z'", doc.CreationInfo.Created)
+		}
+		if len(doc.CreationInfo.Creators) != 1 || doc.CreationInfo.Creators[0] != "Tool: terraform-sbom-1.0" {
+			t.Errorf("creationInfo.creators = %v, want [Tool: terraform-sbom-1.0]", doc.CreationInfo.Creators)
+		}
+
+		// Root package plus one package per module call
+		if len(doc.Packages) != 4 {
+			t.Fatalf("len(packages) = %v, want 4", len(doc.Packages))
+		}
+	})
+
+	t.Run("packages", func(t *testing.T) {
+		doc, err := newSPDXDocument(testSBOM)
+		if err != nil {
+			t.Fatalf("newSPDXDocument() = %v, want nil", err)
+		}
+
+		vpc := doc.Packages[1]
+		if vpc.SPDXID != "SPDXRef-Module-vpc" {
+			t.Errorf("vpc.SPDXID = %v, want 'SPDXRef-Module-vpc'", vpc.SPDXID)
+		}
+		if vpc.VersionInfo != "5.1.0" {
+			t.Errorf("vpc.VersionInfo = %v, want '5.1.0'", vpc.VersionInfo)
+		}
+		if vpc.DownloadLocation != "https://registry.terraform.io/v1/modules/terraform-aws-modules/vpc/aws/5.1.0/download" {
+			t.Errorf("vpc.DownloadLocation = %v", vpc.DownloadLocation)
+		}
+		if len(vpc.ExternalRefs) != 1 || vpc.ExternalRefs[0].ReferenceType != "purl" {
+			t.Errorf("vpc.ExternalRefs = %v, want a single purl reference", vpc.ExternalRefs)
+		}
+
+		// Underscores are not valid in SPDX identifiers and duplicates must stay unique
+		if doc.Packages[2].SPDXID != "SPDXRef-Module-network-core" {
+			t.Errorf("packages[2].SPDXID = %v, want 'SPDXRef-Module-network-core'", doc.Packages[2].SPDXID)
+		}
+		if doc.Packages[3].SPDXID != "SPDXRef-Module-network-core-2" {
+			t.Errorf("packages[3].SPDXID = %v, want 'SPDXRef-Module-network-core-2'", doc.Packages[3].SPDXID)
+		}
+		if doc.Packages[2].DownloadLocation != "git+https://github.com/example/network.git@v1.2.0" {
+			t.Errorf("packages[2].DownloadLocation = %v", doc.Packages[2].DownloadLocation)
+		}
+		if doc.Packages[3].DownloadLocation != "NOASSERTION" {
+			t.Errorf("packages[3].DownloadLocation = %v, want 'NOASSERTION'", doc.Packages[3].DownloadLocation)
+		}
+	})
+
+	t.Run("relationships", func(t *testing.T) {
+		doc, err := newSPDXDocument(testSBOM)
+		if err != nil {
+			t.Fatalf("newSPDXDocument() = %v, want nil", err)
+		}
+
+		if len(doc.Relationships) != 4 {
+			t.Fatalf("len(relationships) = %v, want 4", len(doc.Relationships))
+		}
+		describes := doc.Relationships[0]
+		if describes.SPDXElementID != "SPDXRef-DOCUMENT" || describes.RelationshipType != "DESCRIBES" || describes.RelatedSPDXElement != spdxRootID {
+			t.Errorf("relationships[0] = %+v, want DOCUMENT DESCRIBES root", describes)
+		}
+		for i, rel := range doc.Relationships[1:] {
+			if rel.SPDXElementID != spdxRootID || rel.RelationshipType != "DEPENDS_ON" {
+				t.Errorf("relationships[%d] = %+v, want root DEPENDS_ON module", i+1, rel)
+			}
+			if rel.RelatedSPDXElement != doc.Packages[i+1].SPDXID {
+				t.Errorf("relationships[%d].relatedSpdxElement = %v, want %v", i+1, rel.RelatedSPDXElement, doc.Packages[i+1].SPDXID)
+			}
+		}
+	})
+}
+
+func TestSPDXDownloadLocation(t *testing.T) {
+	tests := []struct {
+		source   string
+		version  string
+		expected string
+	}{
+		{"terraform-aws-modules/vpc/aws", "~> 5.0", "https://registry.terraform.io/v1/modules/terraform-aws-modules/vpc/aws"},
+		{"app.terraform.io/example-corp/k8s-cluster/azurerm", "1.0.0", "https://app.terraform.io/v1/modules/example-corp/k8s-cluster/azurerm/1.0.0/download"},
+		{"github.com/hashicorp/example", "", "git+https://github.com/hashicorp/example"},
+		{"git::https://example.com/vpc.git?ref=v1.2.0", "", "git+https://example.com/vpc.git@v1.2.0"},
+		{"git::ssh://git@example.com/storage.git", "", "git+ssh://git@example.com/storage.git"},
+		{"git@github.com:hashicorp/example.git", "", "git+ssh://git@github.com/hashicorp/example.git"},
+		{"hg::http://example.com/vpc.hg?ref=default", "", "hg+http://example.com/vpc.hg@default"},
+		{"https://example.com/vpc-module.zip", "", "https://example.com/vpc-module.zip"},
+		{"s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip", "", "https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip"},
+		{"gcs::https://www.googleapis.com/storage/v1/modules/foomodule.zip", "", "https://www.googleapis.com/storage/v1/modules/foomodule.zip"},
+		{"./modules/local", "", "NOASSERTION"},
+		{"../shared", "", "NOASSERTION"},
+		{"", "", "NOASSERTION"},
+	}
+
+	for _, test := range tests {
+		result := spdxDownloadLocation(sbom.ModuleInfo{Source: test.source, Version: test.version})
+		if result != test.expected {
+			t.Errorf("spdxDownloadLocation(%q) = %q, want %q", test.source, result, test.expected)
+		}
+	}
+}
+
+func TestSPDXProviderPackage(t *testing.T) {
+	testSBOM := &sbom.SBOM{
+		Version:   "1.0",
+		Generated: "2024-01-02T03:04:05Z",
+		Tool:      "terraform-sbom",
+		Providers: []sbom.ProviderInfo{
+			{
+				Name:               "aws",
+				Source:             "registry.terraform.io/hashicorp/aws",
+				VersionConstraints: []string{"~> 5.0"},
+				Location:           "Provider requirement at /project/versions.tf:4",
+				Filename:           "/project/versions.tf",
+				Directory:          "/project",
+			},
+		},
+	}
+
+	doc, err := newSPDXDocument(testSBOM)
+	if err != nil {
+		t.Fatalf("newSPDXDocument() = %v, want nil", err)
+	}
+
+	if len(doc.Packages) != 2 {
+		t.Fatalf("len(packages) = %v, want 2", len(doc.Packages))
+	}
+	aws := doc.Packages[1]
+	if aws.SPDXID != "SPDXRef-Provider-aws" {
+		t.Errorf("aws.SPDXID = %v, want 'SPDXRef-Provider-aws'", aws.SPDXID)
+	}
+	if aws.VersionInfo != "~> 5.0" {
+		t.Errorf("aws.VersionInfo = %v, want '~> 5.0'", aws.VersionInfo)
+	}
+	if aws.DownloadLocation != "https://registry.terraform.io/v1/providers/hashicorp/aws" {
+		t.Errorf("aws.DownloadLocation = %v", aws.DownloadLocation)
+	}
+
+	if len(doc.Relationships) != 2 {
+		t.Fatalf("len(relationships) = %v, want 2", len(doc.Relationships))
+	}
+	if rel := doc.Relationships[1]; rel.RelationshipType != "DEPENDS_ON" || rel.RelatedSPDXElement != aws.SPDXID {
+		t.Errorf("relationships[1] = %+v, want root DEPENDS_ON aws", rel)
+	}
+}
+
+func TestSPDXIDAllocator(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		expected []string
+	}{
+		{"distinct names", []string{"a", "b"}, []string{"SPDXRef-a", "SPDXRef-b"}},
+		{"duplicates are numbered", []string{"a", "a", "a"}, []string{"SPDXRef-a", "SPDXRef-a-2", "SPDXRef-a-3"}},
+		{"numbered name after duplicate", []string{"a", "a", "a-2"}, []string{"SPDXRef-a", "SPDXRef-a-2", "SPDXRef-a-2-2"}},
+		{"duplicate after numbered name", []string{"a-2", "a", "a"}, []string{"SPDXRef-a-2", "SPDXRef-a", "SPDXRef-a-3"}},
+		{"sanitized collisions", []string{"a_b", "a.b", "a b"}, []string{"SPDXRef-a-b", "SPDXRef-a.b", "SPDXRef-a-b-2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := newSPDXIDAllocator()
+			var got []string
+			for _, name := range tt.names {
+				got = append(got, ids.allocate(name))
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("allocate(%v) = %v, want %v", tt.names, got, tt.expected)
+			}
+		})
+	}
+
+	// Modules named a, a and a-2 must still get distinct packages
+	s := &sbom.SBOM{Modules: []sbom.ModuleInfo{{Name: "a"}, {Name: "a"}, {Name: "a-2"}}}
+	doc, err := newSPDXDocument(s)
+	if err != nil {
+		t.Fatalf("newSPDXDocument() = %v, want nil", err)
+	}
+	seen := make(map[string]bool)
+	for _, pkg := range doc.Packages {
+		if seen[pkg.SPDXID] {
+			t.Errorf("SPDXID %q is used by more than one package", pkg.SPDXID)
+		}
+		seen[pkg.SPDXID] = true
+	}
+}
+
+func TestSPDXNestedModuleRelationships(t *testing.T) {
+	doc, err := newSPDXDocument(nestedModulesSBOM)
+	if err != nil {
+		t.Fatalf("newSPDXDocument() = %v, want nil", err)
+	}
+
+	expected := []spdxRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxRootID},
+		{SPDXElementID: spdxRootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-network"},
+		{SPDXElementID: "SPDXRef-Module-network", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-vpc"},
+		{SPDXElementID: spdxRootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-dns"},
+	}
+	if !reflect.DeepEqual(doc.Relationships, expected) {
+		t.Errorf("Relationships = %+v, want %+v", doc.Relationships, expected)
+	}
+}
+
+// childFirstModulesSBOM lists a nested module call before its parent, as sorting by file
+// does when the called module lives in a directory that sorts before its caller
+var childFirstModulesSBOM = &sbom.SBOM{
+	Version:   "1.0",
+	Generated: "2024-01-02T03:04:05Z",
+	Tool:      "terraform-sbom",
+	Modules: []sbom.ModuleInfo{
+		{Name: "vpc", Source: "terraform-aws-modules/vpc/aws", Version: "5.1.2", Location: "Module call at /project/modules/net/main.tf:1", Filename: "/project/modules/net/main.tf", Root: "/project/stacks/app", Path: "module.net.module.vpc", Parent: "module.net", Depth: 2},
+		{Name: "net", Source: "../../modules/net", Location: "Module call at /project/stacks/app/main.tf:1", Filename: "/project/stacks/app/main.tf", Root: "/project/stacks/app", Path: "module.net", Depth: 1},
+	},
+}
+
+func TestSPDXChildBeforeParent(t *testing.T) {
+	doc, err := newSPDXDocument(childFirstModulesSBOM)
+	if err != nil {
+		t.Fatalf("newSPDXDocument() = %v, want nil", err)
+	}
+
+	expected := []spdxRelationship{
+		{SPDXElementID: "SPDXRef-DOCUMENT", RelationshipType: "DESCRIBES", RelatedSPDXElement: spdxRootID},
+		{SPDXElementID: "SPDXRef-Module-net", RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-vpc"},
+		{SPDXElementID: spdxRootID, RelationshipType: "DEPENDS_ON", RelatedSPDXElement: "SPDXRef-Module-net"},
+	}
+	if !reflect.DeepEqual(doc.Relationships, expected) {
+		t.Errorf("Relationships = %+v, want %+v", doc.Relationships, expected)
+	}
+}
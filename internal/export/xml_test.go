@@ -111,8 +111,8 @@ func TestExportXML(t *testing.T) {
 		if modules[0].Version != "~> 5.0" {
 			t.Errorf("modules[0].Version = %v, want '~> 5.0'", modules[0].Version)
 		}
-		if modules[0].Location != "Module call at main.tf:10" {
-			t.Errorf("modules[0].Location = %v, want 'Module call at main.tf:10'", modules[0].Location)
+		if modules[0].Location != "Module call at /project/main.tf:10" {
+			t.Errorf("modules[0].Location = %v, want 'Module call at /project/main.tf:10'", modules[0].Location)
 		}
 
 		// Verify second module (without version)
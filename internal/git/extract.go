@@ -0,0 +1,67 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// Extract writes the files of revision for which match returns true into dest, keeping
+// their paths relative to the repository root. match receives the slash-separated path
+// of each file. Symbolic links and submodules are skipped. It returns the number of
+// files written.
+func (r *Repository) Extract(revision, dest string, match func(name string) bool) (int, error) {
+	h, err := r.ResolveRevision(revision)
+	if err != nil {
+		return 0, err
+	}
+	c, err := r.readCommit(h)
+	if err != nil {
+		return 0, err
+	}
+	return r.extractTree(c.tree, "", dest, match)
+}
+
+// extractTree writes the matching files below the tree at dir into dest
+func (r *Repository) extractTree(tree Hash, dir, dest string, match func(name string) bool) (int, error) {
+	entries, err := r.ReadTree(tree)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, entry := range entries {
+		name := path.Join(dir, entry.Name)
+		switch entry.Mode {
+		case ModeDirectory:
+			n, err := r.extractTree(entry.Hash, name, dest, match)
+			if err != nil {
+				return count, err
+			}
+			count += n
+		case ModeFile, ModeExecutable:
+			if !match(name) {
+				continue
+			}
+			data, err := r.readTyped(entry.Hash, ObjectBlob)
+			if err != nil {
+				return count, err
+			}
+			// Entry names are validated by ReadTree; this also guards against names that
+			// are special on the local system, such as those containing a backslash
+			if !filepath.IsLocal(filepath.FromSlash(name)) {
+				return count, fmt.Errorf("refusing to extract %q outside %s", name, dest)
+			}
+			target := filepath.Join(dest, filepath.FromSlash(name))
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return count, fmt.Errorf("failed to create directory for %s: %w", name, err)
+			}
+			if err := os.WriteFile(target, data, 0644); err != nil {
+				return count, fmt.Errorf("failed to write %s: %w", name, err)
+			}
+			count++
+		}
+	}
+	return count, nil
+}
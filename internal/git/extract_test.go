@@ -0,0 +1,224 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto/sha1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	repo := newTestRepository(t)
+
+	// Large enough for gc to store the second version as a delta of the first
+	variables := strings.Repeat("variable \"name\" {\n  type = string\n}\n\n", 50)
+	repo.commit("first", map[string]string{
+		"main.tf":                `module "vpc" { source = "./modules/vpc" }`,
+		"variables.tf":           variables,
+		"modules/vpc/main.tf":    "# vpc\n",
+		"modules/vpc/README.md":  "# VPC\n",
+		"modules/vpc/outputs.tf": "# outputs\n",
+	})
+	repo.commit("second", map[string]string{
+		"variables.tf": variables + "# changed\n",
+	})
+	if err := os.Symlink("main.tf", filepath.Join(repo.dir, "link.tf")); err != nil {
+		t.Fatalf("failed to create symlink: %v", err)
+	}
+	repo.commit("third", nil)
+
+	matchTerraform := func(name string) bool { return strings.HasSuffix(name, ".tf") }
+
+	for _, packed := range []bool{false, true} {
+		if packed {
+			repo.git("gc", "-q", "--aggressive")
+		}
+		r, err := Open(repo.dir)
+		if err != nil {
+			t.Fatalf("Open() = %v, want nil", err)
+		}
+
+		for _, tt := range []struct {
+			revision string
+			want     string
+		}{
+			{"HEAD~2", variables},
+			{"HEAD", variables + "# changed\n"},
+		} {
+			name := tt.revision
+			if packed {
+				name += " packed"
+			}
+			t.Run(name, func(t *testing.T) {
+				dest := t.TempDir()
+				count, err := r.Extract(tt.revision, dest, matchTerraform)
+				if err != nil {
+					t.Fatalf("Extract() = %v, want nil", err)
+				}
+				// The symbolic link and README are skipped
+				if count != 4 {
+					t.Errorf("Extract() = %v, want 4 files", count)
+				}
+
+				content, err := os.ReadFile(filepath.Join(dest, "variables.tf"))
+				if err != nil {
+					t.Fatalf("failed to read variables.tf: %v", err)
+				}
+				if string(content) != tt.want {
+					t.Errorf("variables.tf has %d bytes, want %d", len(content), len(tt.want))
+				}
+				if _, err := os.Stat(filepath.Join(dest, "modules", "vpc", "main.tf")); err != nil {
+					t.Errorf("modules/vpc/main.tf was not extracted: %v", err)
+				}
+				if _, err := os.Lstat(filepath.Join(dest, "modules", "vpc", "README.md")); err == nil {
+					t.Error("README.md was extracted, want only matching files")
+				}
+				if _, err := os.Lstat(filepath.Join(dest, "link.tf")); err == nil {
+					t.Error("link.tf was extracted, want symbolic links skipped")
+				}
+			})
+		}
+	}
+}
+
+func TestApplyDelta(t *testing.T) {
+	base := []byte("hello, world")
+
+	tests := []struct {
+		name    string
+		delta   []byte
+		want    string
+		wantErr bool
+	}{
+		// Sizes 12 and 11, copy "hello" then insert " git!"
+		{"copy and insert", []byte{12, 10, 0x90, 5, 5, ' ', 'g', 'i', 't', '!'}, "hello git!", false},
+		// Copy "world" from offset 7
+		{"copy with offset", []byte{12, 5, 0x91, 7, 5}, "world", false},
+		{"wrong base size", []byte{3, 0}, "", true},
+		{"copy out of range", []byte{12, 5, 0x91, 10, 5}, "", true},
+		{"truncated insert", []byte{12, 5, 5, 'a'}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyDelta(base, tt.delta)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("applyDelta() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if string(got) != tt.want {
+				t.Errorf("applyDelta() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// writeLooseObject stores an object directly in the repository, bypassing the checks
+// git applies to trees it creates, and returns its name
+func writeLooseObject(t *testing.T, repo *testRepository, objectType string, content []byte) Hash {
+	t.Helper()
+	data := append([]byte(fmt.Sprintf("%s %d\x00", objectType, len(content))), content...)
+	h := Hash(sha1.Sum(data))
+
+	var buffer bytes.Buffer
+	z := zlib.NewWriter(&buffer)
+	if _, err := z.Write(data); err != nil {
+		t.Fatalf("failed to compress object: %v", err)
+	}
+	if err := z.Close(); err != nil {
+		t.Fatalf("failed to compress object: %v", err)
+	}
+
+	name := h.String()
+	path := filepath.Join(repo.dir, ".git", "objects", name[:2], name[2:])
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("failed to create object directory: %v", err)
+	}
+	if err := os.WriteFile(path, buffer.Bytes(), 0444); err != nil {
+		t.Fatalf("failed to write object: %v", err)
+	}
+	return h
+}
+
+// treeEntry encodes a single tree entry
+func treeEntry(mode, name string, h Hash) []byte {
+	return append([]byte(mode+" "+name+"\x00"), h[:]...)
+}
+
+func TestExtractRejectsUnsafeNames(t *testing.T) {
+	repo := newTestRepository(t)
+	repo.commit("first", map[string]string{"main.tf": "# main\n"})
+	blob, err := ParseHash(repo.git("rev-parse", "HEAD:main.tf"))
+	if err != nil {
+		t.Fatalf("ParseHash() = %v, want nil", err)
+	}
+	inner := writeLooseObject(t, repo, ObjectTree, treeEntry(ModeFile, "escape.tf", blob))
+
+	tests := []struct {
+		name  string
+		entry []byte
+	}{
+		{"parent directory", treeEntry(ModeDirectory, "..", inner)},
+		{"current directory", treeEntry(ModeDirectory, ".", inner)},
+		{"slash in name", treeEntry(ModeFile, "../escape.tf", blob)},
+		{"git directory", treeEntry(ModeDirectory, ".GIT", inner)},
+		{"empty name", treeEntry(ModeFile, "", blob)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := writeLooseObject(t, repo, ObjectTree, tt.entry)
+			commit := repo.git("commit-tree", tree.String(), "-m", tt.name)
+
+			r, err := Open(repo.dir)
+			if err != nil {
+				t.Fatalf("Open() = %v, want nil", err)
+			}
+			if _, err := r.ReadTree(tree); err == nil || !strings.Contains(err.Error(), "invalid entry name") {
+				t.Errorf("ReadTree() = %v, want an invalid entry name error", err)
+			}
+
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "dest")
+			if _, err := r.Extract(commit, dest, func(string) bool { return true }); err == nil {
+				t.Error("Extract() = nil, want error for an unsafe tree")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "escape.tf")); err == nil {
+				t.Error("escape.tf was written outside the destination")
+			}
+		})
+	}
+}
+
+func TestInflate(t *testing.T) {
+	var buffer bytes.Buffer
+	z := zlib.NewWriter(&buffer)
+	z.Write([]byte("hello"))
+	z.Close()
+
+	tests := []struct {
+		name    string
+		size    int64
+		wantErr bool
+	}{
+		{"exact size", 5, false},
+		{"longer than stream", 10, true},
+		// A corrupt header must not allocate the claimed size up front
+		{"huge size", 1 << 50, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := inflate(bytes.NewReader(buffer.Bytes()), tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("inflate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && string(data) != "hello" {
+				t.Errorf("inflate() = %q, want hello", data)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package git
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Object types as stored in loose objects and pack files
+const (
+	ObjectCommit = "commit"
+	ObjectTree   = "tree"
+	ObjectBlob   = "blob"
+	ObjectTag    = "tag"
+)
+
+// ReadObject returns the type and content of an object
+func (r *Repository) ReadObject(h Hash) (string, []byte, error) {
+	objectType, data, ok, err := r.readLooseObject(h)
+	if err != nil || ok {
+		return objectType, data, err
+	}
+
+	packs, err := r.loadPacks()
+	if err != nil {
+		return "", nil, err
+	}
+	for _, p := range packs {
+		if offset, ok := p.find(h); ok {
+			return p.readObject(r, offset)
+		}
+	}
+
+	return "", nil, fmt.Errorf("object %s not found", h)
+}
+
+// readLooseObject reads an object stored in its own zlib-compressed file
+func (r *Repository) readLooseObject(h Hash) (string, []byte, bool, error) {
+	name := h.String()
+	file, err := os.Open(filepath.Join(r.commonDir, "objects", name[:2], name[2:]))
+	if err != nil {
+		return "", nil, false, nil
+	}
+	defer file.Close()
+
+	reader, err := zlib.NewReader(file)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to read object %s: %w", name, err)
+	}
+	defer reader.Close()
+
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to read object %s: %w", name, err)
+	}
+
+	// The content is prefixed with "<type> <size>\x00"
+	header, data, ok := bytes.Cut(content, []byte{0})
+	if !ok {
+		return "", nil, false, fmt.Errorf("invalid object %s: missing header", name)
+	}
+	objectType, size, ok := strings.Cut(string(header), " ")
+	if !ok || size != strconv.Itoa(len(data)) {
+		return "", nil, false, fmt.Errorf("invalid object %s: bad header %q", name, header)
+	}
+	return objectType, data, true, nil
+}
+
+// readTyped reads an object and checks its type
+func (r *Repository) readTyped(h Hash, want string) ([]byte, error) {
+	objectType, data, err := r.ReadObject(h)
+	if err != nil {
+		return nil, err
+	}
+	if objectType != want {
+		return nil, fmt.Errorf("object %s is a %s, not a %s", h, objectType, want)
+	}
+	return data, nil
+}
+
+// commit holds the fields of a commit object used to walk history and trees
+type commit struct {
+	hash    Hash
+	tree    Hash
+	parents []Hash
+}
+
+// readCommit parses a commit object
+func (r *Repository) readCommit(h Hash) (*commit, error) {
+	data, err := r.readTyped(h, ObjectCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &commit{hash: h}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line == "" {
+			break
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "tree":
+			if c.tree, err = ParseHash(value); err != nil {
+				return nil, fmt.Errorf("invalid commit %s: %w", h, err)
+			}
+		case "parent":
+			parent, err := ParseHash(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid commit %s: %w", h, err)
+			}
+			c.parents = append(c.parents, parent)
+		}
+	}
+	return c, nil
+}
+
+// peelToCommit follows annotated tags until it reaches a commit
+func (r *Repository) peelToCommit(h Hash) (*commit, error) {
+	for range 10 {
+		objectType, data, err := r.ReadObject(h)
+		if err != nil {
+			return nil, err
+		}
+		switch objectType {
+		case ObjectCommit:
+			return r.readCommit(h)
+		case ObjectTag:
+			target, _, _ := strings.Cut(strings.TrimPrefix(string(data), "object "), "\n")
+			next, err := ParseHash(target)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tag %s: %w", h, err)
+			}
+			h = next
+		default:
+			return nil, fmt.Errorf("object %s is a %s, not a commit", h, objectType)
+		}
+	}
+	return nil, fmt.Errorf("too many levels of tags at %s", h)
+}
+
+// parent returns the nth parent of a commit, counting from one
+func (r *Repository) parent(c *commit, n int) (*commit, error) {
+	if n > len(c.parents) {
+		return nil, fmt.Errorf("commit %s has no parent %d", c.hash, n)
+	}
+	return r.readCommit(c.parents[n-1])
+}
+
+// TreeEntry is a file, directory, symbolic link or submodule in a tree
+type TreeEntry struct {
+	Mode string
+	Name string
+	Hash Hash
+}
+
+// Tree entry modes
+const (
+	ModeDirectory  = "40000"
+	ModeFile       = "100644"
+	ModeExecutable = "100755"
+	ModeSymlink    = "120000"
+	ModeSubmodule  = "160000"
+)
+
+// ReadTree returns the entries of a tree object
+func (r *Repository) ReadTree(h Hash) ([]TreeEntry, error) {
+	data, err := r.readTyped(h, ObjectTree)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each entry is "<mode> <name>\x00<20-byte hash>"
+	var entries []TreeEntry
+	for len(data) > 0 {
+		header, rest, ok := bytes.Cut(data, []byte{0})
+		if !ok || len(rest) < len(Hash{}) {
+			return nil, fmt.Errorf("invalid tree %s", h)
+		}
+		mode, name, ok := strings.Cut(string(header), " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid tree %s", h)
+		}
+
+		if !validEntryName(name) {
+			return nil, fmt.Errorf("invalid tree %s: invalid entry name %q", h, name)
+		}
+
+		entry := TreeEntry{Mode: mode, Name: name}
+		copy(entry.Hash[:], rest)
+		entries = append(entries, entry)
+		data = rest[len(Hash{}):]
+	}
+	return entries, nil
+}
+
+// validEntryName reports whether name can be a single path component of a tree. As
+// with git fsck, empty names, ".", "..", ".git" and names containing a slash are
+// rejected so that a crafted tree cannot write outside the directory it is read into.
+func validEntryName(name string) bool {
+	switch name {
+	case "", ".", "..":
+		return false
+	}
+	return !strings.EqualFold(name, ".git") && !strings.Contains(name, "/")
+}
@@ -0,0 +1,323 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Pack object types; 5 is reserved
+const (
+	packCommit   = 1
+	packTree     = 2
+	packBlob     = 3
+	packTag      = 4
+	packOfsDelta = 6
+	packRefDelta = 7
+)
+
+// packObjectTypes maps non-delta pack object types to object type names
+var packObjectTypes = map[int]string{
+	packCommit: ObjectCommit,
+	packTree:   ObjectTree,
+	packBlob:   ObjectBlob,
+	packTag:    ObjectTag,
+}
+
+// maxCachedBases bounds the number of resolved delta bases kept per pack
+const maxCachedBases = 256
+
+// pack is a pack file and its version 2 index
+type pack struct {
+	path    string
+	hashes  []Hash
+	offsets []int64
+	cache   map[int64]packedObject
+}
+
+// packedObject is an object resolved from a pack file
+type packedObject struct {
+	objectType string
+	data       []byte
+}
+
+// loadPacks reads the index of every pack file in the repository once
+func (r *Repository) loadPacks() ([]*pack, error) {
+	if r.packsRead {
+		return r.packs, nil
+	}
+
+	indexes, err := filepath.Glob(filepath.Join(r.commonDir, "objects", "pack", "*.idx"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pack files: %w", err)
+	}
+	sort.Strings(indexes)
+	for _, index := range indexes {
+		p, err := readPackIndex(index)
+		if err != nil {
+			return nil, err
+		}
+		r.packs = append(r.packs, p)
+	}
+	r.packsRead = true
+	return r.packs, nil
+}
+
+// readPackIndex reads a version 2 pack index
+func readPackIndex(path string) (*pack, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack index %s: %w", path, err)
+	}
+
+	// Header, 256-entry fanout table, then names, CRCs, offsets and large offsets
+	const headerSize = 8 + 256*4
+	if len(content) < headerSize || !bytes.Equal(content[:4], []byte{0xff, 't', 'O', 'c'}) || binary.BigEndian.Uint32(content[4:8]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index %s: only version 2 is supported", path)
+	}
+	count := int(binary.BigEndian.Uint32(content[headerSize-4 : headerSize]))
+
+	namesStart := headerSize
+	offsetsStart := namesStart + count*20 + count*4
+	largeStart := offsetsStart + count*4
+	if len(content) < largeStart {
+		return nil, fmt.Errorf("invalid pack index %s: truncated", path)
+	}
+
+	p := &pack{
+		path:    strings.TrimSuffix(path, ".idx") + ".pack",
+		hashes:  make([]Hash, count),
+		offsets: make([]int64, count),
+		cache:   make(map[int64]packedObject),
+	}
+	for i := 0; i < count; i++ {
+		copy(p.hashes[i][:], content[namesStart+i*20:])
+
+		offset := binary.BigEndian.Uint32(content[offsetsStart+i*4:])
+		if offset&0x80000000 == 0 {
+			p.offsets[i] = int64(offset)
+			continue
+		}
+		// Offsets beyond 2GiB are stored in the large offset table
+		large := largeStart + int(offset&0x7fffffff)*8
+		if len(content) < large+8 {
+			return nil, fmt.Errorf("invalid pack index %s: truncated large offsets", path)
+		}
+		p.offsets[i] = int64(binary.BigEndian.Uint64(content[large:]))
+	}
+	return p, nil
+}
+
+// find returns the offset of an object in the pack
+func (p *pack) find(h Hash) (int64, bool) {
+	i := sort.Search(len(p.hashes), func(i int) bool { return bytes.Compare(p.hashes[i][:], h[:]) >= 0 })
+	if i < len(p.hashes) && p.hashes[i] == h {
+		return p.offsets[i], true
+	}
+	return 0, false
+}
+
+// findPrefix returns the objects in the pack whose hexadecimal name starts with prefix
+func (p *pack) findPrefix(prefix string) []Hash {
+	var matches []Hash
+	start := sort.Search(len(p.hashes), func(i int) bool { return hex.EncodeToString(p.hashes[i][:]) >= prefix })
+	for i := start; i < len(p.hashes) && strings.HasPrefix(p.hashes[i].String(), prefix); i++ {
+		matches = append(matches, p.hashes[i])
+	}
+	return matches
+}
+
+// readObject reads the object at offset, applying deltas against its base objects
+func (p *pack) readObject(r *Repository, offset int64) (string, []byte, error) {
+	if cached, ok := p.cache[offset]; ok {
+		return cached.objectType, cached.data, nil
+	}
+
+	file, err := os.Open(p.path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open pack %s: %w", p.path, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(io.NewSectionReader(file, offset, 1<<62))
+
+	// Type and inflated size, seven bits at a time after the first four
+	b, err := reader.ReadByte()
+	if err != nil {
+		return "", nil, p.corrupt(offset, err)
+	}
+	packType := int(b>>4) & 7
+	size := int64(b & 0x0f)
+	for shift := 4; b&0x80 != 0; shift += 7 {
+		if b, err = reader.ReadByte(); err != nil {
+			return "", nil, p.corrupt(offset, err)
+		}
+		size |= int64(b&0x7f) << shift
+	}
+
+	var baseType string
+	var base []byte
+	switch packType {
+	case packOfsDelta:
+		// The base is stored earlier in the same pack, at a relative offset
+		b, err := reader.ReadByte()
+		if err != nil {
+			return "", nil, p.corrupt(offset, err)
+		}
+		relative := int64(b & 0x7f)
+		for b&0x80 != 0 {
+			if b, err = reader.ReadByte(); err != nil {
+				return "", nil, p.corrupt(offset, err)
+			}
+			relative = ((relative + 1) << 7) | int64(b&0x7f)
+		}
+		if baseType, base, err = p.readObject(r, offset-relative); err != nil {
+			return "", nil, err
+		}
+	case packRefDelta:
+		var baseHash Hash
+		if _, err := io.ReadFull(reader, baseHash[:]); err != nil {
+			return "", nil, p.corrupt(offset, err)
+		}
+		if baseType, base, err = r.ReadObject(baseHash); err != nil {
+			return "", nil, err
+		}
+	}
+
+	data, err := inflate(reader, size)
+	if err != nil {
+		return "", nil, p.corrupt(offset, err)
+	}
+
+	objectType := packObjectTypes[packType]
+	if base != nil {
+		objectType = baseType
+		if data, err = applyDelta(base, data); err != nil {
+			return "", nil, p.corrupt(offset, err)
+		}
+	}
+	if objectType == "" {
+		return "", nil, p.corrupt(offset, fmt.Errorf("unknown object type %d", packType))
+	}
+
+	if len(p.cache) >= maxCachedBases {
+		clear(p.cache)
+	}
+	p.cache[offset] = packedObject{objectType: objectType, data: data}
+	return objectType, data, nil
+}
+
+// corrupt wraps an error reading the object at offset
+func (p *pack) corrupt(offset int64, err error) error {
+	return fmt.Errorf("failed to read object at offset %d in %s: %w", offset, p.path, err)
+}
+
+// inflate decompresses a zlib stream of the given inflated size. The size comes from
+// the pack, so the buffer grows as data is read rather than being allocated up front.
+func inflate(reader io.Reader, size int64) ([]byte, error) {
+	z, err := zlib.NewReader(reader)
+	if err != nil {
+		return nil, err
+	}
+	defer z.Close()
+
+	data, err := io.ReadAll(io.LimitReader(z, size))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) != size {
+		return nil, fmt.Errorf("inflated size %d does not match %d", len(data), size)
+	}
+	return data, nil
+}
+
+// applyDelta rebuilds an object from its base and a delta of copy and insert instructions
+func applyDelta(base, delta []byte) ([]byte, error) {
+	sourceSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+	if sourceSize != len(base) {
+		return nil, fmt.Errorf("delta base size %d does not match %d", sourceSize, len(base))
+	}
+	targetSize, delta, err := deltaSize(delta)
+	if err != nil {
+		return nil, err
+	}
+
+	// The target size is untrusted, so only reserve what the base and delta can plausibly hold
+	result := make([]byte, 0, min(targetSize, len(base)+len(delta)))
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case cmd&0x80 != 0:
+			// Copy from the base; the low bits select which offset and size bytes follow
+			var offset, size int
+			for i := 0; i < 4; i++ {
+				if cmd&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, fmt.Errorf("truncated delta")
+					}
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if cmd&(0x10<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, fmt.Errorf("truncated delta")
+					}
+					size |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, fmt.Errorf("delta copy out of range")
+			}
+			result = append(result, base[offset:offset+size]...)
+		case cmd != 0:
+			// Insert the next cmd bytes
+			if int(cmd) > len(delta) {
+				return nil, fmt.Errorf("truncated delta")
+			}
+			result = append(result, delta[:cmd]...)
+			delta = delta[cmd:]
+		default:
+			return nil, fmt.Errorf("invalid delta instruction")
+		}
+	}
+
+	if len(result) != targetSize {
+		return nil, fmt.Errorf("delta result size %d does not match %d", len(result), targetSize)
+	}
+	return result, nil
+}
+
+// deltaSize reads a little-endian base-128 size from the start of a delta
+func deltaSize(delta []byte) (int, []byte, error) {
+	size := 0
+	for shift := 0; ; shift += 7 {
+		if len(delta) == 0 {
+			return 0, nil, fmt.Errorf("truncated delta header")
+		}
+		b := delta[0]
+		delta = delta[1:]
+		size |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			return size, delta, nil
+		}
+	}
+}
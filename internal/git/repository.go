@@ -0,0 +1,282 @@
+package git
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Hash is a SHA-1 object name
+type Hash [20]byte
+
+// String returns the hexadecimal form of the hash
+func (h Hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+// ParseHash parses a full 40-character hexadecimal object name
+func ParseHash(s string) (Hash, error) {
+	var h Hash
+	if len(s) != 40 {
+		return h, fmt.Errorf("invalid object name %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("invalid object name %q: %w", s, err)
+	}
+	return h, nil
+}
+
+// Repository reads objects and references directly from a .git directory
+type Repository struct {
+	root      string
+	gitDir    string
+	commonDir string
+	packs     []*pack
+	packsRead bool
+}
+
+// Open opens the repository whose work tree contains path
+func Open(path string) (*Repository, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	for {
+		dotGit := filepath.Join(dir, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			gitDir := dotGit
+			if !info.IsDir() {
+				// Worktrees and submodules point at their git directory from a .git file
+				gitDir, err = readGitFile(dotGit)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return newRepository(dir, gitDir)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, fmt.Errorf("not a git repository (or any parent up to /): %s", path)
+		}
+		dir = parent
+	}
+}
+
+// newRepository creates a repository for a work tree root and its git directory
+func newRepository(root, gitDir string) (*Repository, error) {
+	commonDir := gitDir
+	if content, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = strings.TrimSpace(string(content))
+		if !filepath.IsAbs(commonDir) {
+			commonDir = filepath.Join(gitDir, commonDir)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(commonDir, "objects")); err != nil {
+		return nil, fmt.Errorf("failed to open git directory %s: %w", gitDir, err)
+	}
+	return &Repository{root: root, gitDir: gitDir, commonDir: commonDir}, nil
+}
+
+// readGitFile returns the git directory named by a "gitdir: <path>" file
+func readGitFile(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir: ")
+	if !ok {
+		return "", fmt.Errorf("invalid git file %s", path)
+	}
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(path), gitDir)
+	}
+	return gitDir, nil
+}
+
+// Root returns the top-level directory of the work tree
+func (r *Repository) Root() string {
+	return r.root
+}
+
+// ResolveRevision resolves a revision such as "HEAD", "main", "origin/main", "v1.0",
+// a full or abbreviated object name, optionally followed by "~N" or "^N" suffixes, to
+// the commit it names
+func (r *Repository) ResolveRevision(revision string) (Hash, error) {
+	base := revision
+	suffixes := ""
+	if idx := strings.IndexAny(revision, "~^"); idx != -1 {
+		base, suffixes = revision[:idx], revision[idx:]
+	}
+
+	h, err := r.resolveName(base)
+	if err != nil {
+		return Hash{}, err
+	}
+	commit, err := r.peelToCommit(h)
+	if err != nil {
+		return Hash{}, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+	}
+
+	for suffixes != "" {
+		op := suffixes[0]
+		suffixes = suffixes[1:]
+		digits := len(suffixes) - len(strings.TrimLeft(suffixes, "0123456789"))
+		n := 1
+		if digits > 0 {
+			n, _ = strconv.Atoi(suffixes[:digits])
+			suffixes = suffixes[digits:]
+		}
+
+		switch op {
+		case '~':
+			for i := 0; i < n; i++ {
+				if commit, err = r.parent(commit, 1); err != nil {
+					return Hash{}, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+				}
+			}
+		case '^':
+			if n == 0 {
+				continue
+			}
+			if commit, err = r.parent(commit, n); err != nil {
+				return Hash{}, fmt.Errorf("failed to resolve revision %s: %w", revision, err)
+			}
+		}
+	}
+
+	return commit.hash, nil
+}
+
+// resolveName resolves a reference name or object name to the object it points at
+func (r *Repository) resolveName(name string) (Hash, error) {
+	if name == "" {
+		return Hash{}, fmt.Errorf("empty revision")
+	}
+	if h, err := ParseHash(name); err == nil {
+		return h, nil
+	}
+
+	candidates := []string{name}
+	if name != "HEAD" && !strings.HasPrefix(name, "refs/") {
+		candidates = []string{
+			"refs/" + name,
+			"refs/tags/" + name,
+			"refs/heads/" + name,
+			"refs/remotes/" + name,
+			"refs/remotes/" + name + "/HEAD",
+		}
+	}
+	for _, ref := range candidates {
+		h, ok, err := r.readRef(ref, 0)
+		if err != nil {
+			return Hash{}, err
+		}
+		if ok {
+			return h, nil
+		}
+	}
+
+	// Abbreviated object names
+	if len(name) >= 4 && len(name) < 40 && strings.Trim(strings.ToLower(name), "0123456789abcdef") == "" {
+		return r.resolvePrefix(strings.ToLower(name))
+	}
+
+	return Hash{}, fmt.Errorf("unknown revision %s", name)
+}
+
+// readRef reads a loose or packed reference, following symbolic references
+func (r *Repository) readRef(ref string, depth int) (Hash, bool, error) {
+	if depth > 10 {
+		return Hash{}, false, fmt.Errorf("too many levels of symbolic references at %s", ref)
+	}
+
+	// HEAD and other per-worktree references live in the worktree's git directory
+	for _, dir := range []string{r.gitDir, r.commonDir} {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(content))
+		if target, ok := strings.CutPrefix(value, "ref: "); ok {
+			return r.readRef(target, depth+1)
+		}
+		h, err := ParseHash(value)
+		if err != nil {
+			return Hash{}, false, fmt.Errorf("invalid reference %s: %w", ref, err)
+		}
+		return h, true, nil
+	}
+
+	return r.readPackedRef(ref)
+}
+
+// readPackedRef looks up a reference in the packed-refs file
+func (r *Repository) readPackedRef(ref string) (Hash, bool, error) {
+	file, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		return Hash{}, false, nil
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") || strings.HasPrefix(line, "^") {
+			continue
+		}
+		value, name, ok := strings.Cut(line, " ")
+		if !ok || name != ref {
+			continue
+		}
+		h, err := ParseHash(value)
+		if err != nil {
+			return Hash{}, false, fmt.Errorf("invalid packed reference %s: %w", ref, err)
+		}
+		return h, true, nil
+	}
+	if err := scanner.Err(); err != nil {
+		return Hash{}, false, fmt.Errorf("failed to read packed-refs: %w", err)
+	}
+	return Hash{}, false, nil
+}
+
+// resolvePrefix finds the single object whose name starts with prefix
+func (r *Repository) resolvePrefix(prefix string) (Hash, error) {
+	matches := make(map[Hash]bool)
+
+	entries, _ := os.ReadDir(filepath.Join(r.commonDir, "objects", prefix[:2]))
+	for _, entry := range entries {
+		name := prefix[:2] + entry.Name()
+		if strings.HasPrefix(name, prefix) {
+			if h, err := ParseHash(name); err == nil {
+				matches[h] = true
+			}
+		}
+	}
+
+	packs, err := r.loadPacks()
+	if err != nil {
+		return Hash{}, err
+	}
+	for _, p := range packs {
+		for _, h := range p.findPrefix(prefix) {
+			matches[h] = true
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return Hash{}, fmt.Errorf("unknown revision %s", prefix)
+	case 1:
+		for h := range matches {
+			return h, nil
+		}
+	}
+	return Hash{}, fmt.Errorf("ambiguous object name %s", prefix)
+}
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepository wraps a repository created with the git command line
+type testRepository struct {
+	t   *testing.T
+	dir string
+}
+
+// newTestRepository creates an empty repository, skipping the test when git is not installed
+func newTestRepository(t *testing.T) *testRepository {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := &testRepository{t: t, dir: t.TempDir()}
+	repo.git("init", "-q", "-b", "main")
+	return repo
+}
+
+// git runs a git command in the repository and returns its trimmed output
+func (r *testRepository) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_NOSYSTEM=1", "HOME="+r.dir,
+	)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commit writes files and commits them, returning the new commit's object name
+func (r *testRepository) commit(message string, files map[string]string) string {
+	r.t.Helper()
+	for name, content := range files {
+		path := filepath.Join(r.dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			r.t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			r.t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", message)
+	return r.git("rev-parse", "HEAD")
+}
+
+func TestResolveRevision(t *testing.T) {
+	repo := newTestRepository(t)
+	first := repo.commit("first", map[string]string{"main.tf": "# first\n"})
+	repo.git("tag", "-a", "v1.0.0", "-m", "release")
+	repo.git("tag", "light")
+	second := repo.commit("second", map[string]string{"main.tf": "# second\n"})
+	repo.git("checkout", "-q", "-b", "feature")
+	third := repo.commit("third", map[string]string{"main.tf": "# third\n"})
+
+	tests := []struct {
+		name     string
+		revision string
+		want     string
+	}{
+		{"head", "HEAD", third},
+		{"branch", "main", second},
+		{"full ref", "refs/heads/main", second},
+		{"annotated tag", "v1.0.0", first},
+		{"lightweight tag", "light", first},
+		{"full object name", first, first},
+		{"abbreviated object name", second[:7], second},
+		{"first parent", "HEAD~1", second},
+		{"ancestor", "HEAD~2", first},
+		{"caret", "feature^", second},
+		{"combined suffixes", "HEAD^~1", first},
+	}
+
+	for _, packed := range []bool{false, true} {
+		if packed {
+			// Move objects and references into pack files
+			repo.git("gc", "-q", "--aggressive")
+		}
+		r, err := Open(filepath.Join(repo.dir))
+		if err != nil {
+			t.Fatalf("Open() = %v, want nil", err)
+		}
+
+		for _, tt := range tests {
+			name := tt.name
+			if packed {
+				name += " packed"
+			}
+			t.Run(name, func(t *testing.T) {
+				h, err := r.ResolveRevision(tt.revision)
+				if err != nil {
+					t.Fatalf("ResolveRevision(%q) = %v, want nil", tt.revision, err)
+				}
+				if h.String() != tt.want {
+					t.Errorf("ResolveRevision(%q) = %s, want %s", tt.revision, h, tt.want)
+				}
+			})
+		}
+	}
+
+	t.Run("unknown revision", func(t *testing.T) {
+		r, err := Open(repo.dir)
+		if err != nil {
+			t.Fatalf("Open() = %v, want nil", err)
+		}
+		for _, revision := range []string{"missing", "HEAD~5", "ffffffff"} {
+			if _, err := r.ResolveRevision(revision); err == nil {
+				t.Errorf("ResolveRevision(%q) = nil, want error", revision)
+			}
+		}
+	})
+}
+
+func TestOpen(t *testing.T) {
+	repo := newTestRepository(t)
+	repo.commit("first", map[string]string{"infra/main.tf": "# first\n"})
+
+	t.Run("from a subdirectory", func(t *testing.T) {
+		r, err := Open(filepath.Join(repo.dir, "infra"))
+		if err != nil {
+			t.Fatalf("Open() = %v, want nil", err)
+		}
+		if r.Root() != repo.dir {
+			t.Errorf("Root() = %q, want %q", r.Root(), repo.dir)
+		}
+	})
+
+	t.Run("worktree", func(t *testing.T) {
+		worktree := filepath.Join(t.TempDir(), "worktree")
+		repo.git("worktree", "add", "-q", "-b", "other", worktree)
+
+		r, err := Open(worktree)
+		if err != nil {
+			t.Fatalf("Open() = %v, want nil", err)
+		}
+		head, err := r.ResolveRevision("HEAD")
+		if err != nil {
+			t.Fatalf("ResolveRevision(HEAD) = %v, want nil", err)
+		}
+		if want := repo.git("rev-parse", "other"); head.String() != want {
+			t.Errorf("ResolveRevision(HEAD) = %s, want %s", head, want)
+		}
+	})
+
+	t.Run("not a repository", func(t *testing.T) {
+		if _, err := Open(t.TempDir()); err == nil {
+			t.Error("Open() = nil, want error outside a repository")
+		}
+	})
+}
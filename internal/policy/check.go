@@ -0,0 +1,96 @@
+package policy
+
+import (
+	"fmt"
+	"slices"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// Rule identifiers reported with each violation
+const (
+	RuleRegistryVersion  = "registry-version"
+	RulePinnedGitRef     = "pinned-git-ref"
+	RuleDeniedGitRef     = "denied-git-ref"
+	RuleAllowedHost      = "allowed-host"
+	RuleAllowedNamespace = "allowed-namespace"
+)
+
+// RuleDescriptions describes each rule in a sentence, for reports that list rules
+var RuleDescriptions = map[string]string{
+	RuleRegistryVersion:  "Registry modules must declare a version",
+	RulePinnedGitRef:     "Git sources must select a tag or full commit SHA",
+	RuleDeniedGitRef:     "Git sources must not select a denied ref",
+	RuleAllowedHost:      "Module sources must come from an allowed host",
+	RuleAllowedNamespace: "Module sources must come from an allowed namespace",
+}
+
+// Check returns the violations of the policy by the module calls of an SBOM, in module order
+func Check(p *Policy, s *sbom.SBOM) []sbom.PolicyViolation {
+	var violations []sbom.PolicyViolation
+	for _, module := range s.Modules {
+		violations = append(violations, p.checkModule(module)...)
+	}
+	return violations
+}
+
+// checkModule returns the violations of the policy by one module call
+func (p *Policy) checkModule(module sbom.ModuleInfo) []sbom.PolicyViolation {
+	source := sbom.ParseSource(module.Source)
+	if module.ParsedSource != nil {
+		source = *module.ParsedSource
+	}
+	if source.Kind == sbom.SourceLocal {
+		return nil
+	}
+
+	var violations []sbom.PolicyViolation
+	violate := func(rule, format string, args ...any) {
+		violations = append(violations, sbom.PolicyViolation{
+			Rule:     rule,
+			Module:   module.Name,
+			Address:  module.Path,
+			Source:   module.Source,
+			Filename: module.Filename,
+			Line:     module.StartLine(),
+			Column:   module.Column,
+			FilePath: module.FilePath,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if p.RequireRegistryVersion && source.Kind == sbom.SourceRegistry && module.Version == "" {
+		violate(RuleRegistryVersion, "registry module has no version")
+	}
+
+	if isGitSource(source.Kind) {
+		switch {
+		case slices.Contains(p.DeniedGitRefs, source.Ref):
+			violate(RuleDeniedGitRef, "git ref %q is not allowed", source.Ref)
+		case p.RequirePinnedGitRef && source.Ref == "":
+			violate(RulePinnedGitRef, "git source has no ref")
+		case p.RequirePinnedGitRef && !sbom.IsPinnedRef(source.Ref):
+			violate(RulePinnedGitRef, "git ref %q is not a tag or full commit SHA", source.Ref)
+		}
+	}
+
+	// An allow-list rejects sources whose host or namespace cannot be determined
+	if len(p.AllowedHosts) > 0 && !matchAny(p.AllowedHosts, source.Host) {
+		if source.Host == "" {
+			violate(RuleAllowedHost, "source host cannot be determined")
+		} else {
+			violate(RuleAllowedHost, "host %q is not allowed", source.Host)
+		}
+	}
+	if len(p.AllowedNamespaces) > 0 && source.Namespace != "" &&
+		!matchAny(p.AllowedNamespaces, source.Namespace, source.Host+"/"+source.Namespace) {
+		violate(RuleAllowedNamespace, "namespace %q is not allowed", source.Namespace)
+	}
+
+	return violations
+}
+
+// isGitSource reports whether modules of a source kind are fetched with git
+func isGitSource(kind sbom.SourceKind) bool {
+	return kind == sbom.SourceGit || kind == sbom.SourceGitHub || kind == sbom.SourceBitbucket
+}
@@ -0,0 +1,64 @@
+package policy
+
+import (
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		source  string
+		version string
+		want    []string
+	}{
+		{"registry without version", Policy{RequireRegistryVersion: true}, "terraform-aws-modules/vpc/aws", "", []string{RuleRegistryVersion}},
+		{"registry with version", Policy{RequireRegistryVersion: true}, "terraform-aws-modules/vpc/aws", "~> 5.0", nil},
+		{"git without version is not a registry module", Policy{RequireRegistryVersion: true}, "github.com/org/repo?ref=v1.0.0", "", nil},
+		{"git without ref", Policy{RequirePinnedGitRef: true}, "git::https://example.com/app.git", "", []string{RulePinnedGitRef}},
+		{"git branch ref", Policy{RequirePinnedGitRef: true}, "git::https://example.com/app.git?ref=develop", "", []string{RulePinnedGitRef}},
+		{"git short SHA", Policy{RequirePinnedGitRef: true}, "git::https://example.com/app.git?ref=abc1234", "", []string{RulePinnedGitRef}},
+		{"git tag", Policy{RequirePinnedGitRef: true}, "git::https://example.com/app.git?ref=v1.2.0", "", nil},
+		{"git prerelease tag", Policy{RequirePinnedGitRef: true}, "github.com/org/repo?ref=2.0.0-rc.1", "", nil},
+		{"git full SHA", Policy{RequirePinnedGitRef: true}, "git::https://example.com/app.git?ref=0123456789abcdef0123456789abcdef01234567", "", nil},
+		{"http archive has no ref", Policy{RequirePinnedGitRef: true}, "https://example.com/module.zip", "", nil},
+		{"denied ref", Policy{RequirePinnedGitRef: true, DeniedGitRefs: []string{"main", "master"}}, "github.com/org/repo?ref=main", "", []string{RuleDeniedGitRef}},
+		{"allowed host", Policy{AllowedHosts: []string{"registry.terraform.io"}}, "terraform-aws-modules/vpc/aws", "", nil},
+		{"allowed host pattern", Policy{AllowedHosts: []string{"*.example.com"}}, "git::https://git.example.com/app.git", "", nil},
+		{"disallowed host", Policy{AllowedHosts: []string{"registry.terraform.io"}}, "git::https://example.com/app.git", "", []string{RuleAllowedHost}},
+		{"unknown host", Policy{AllowedHosts: []string{"registry.terraform.io"}}, "not a source", "", []string{RuleAllowedHost}},
+		{"allowed namespace", Policy{AllowedNamespaces: []string{"my-org"}}, "github.com/my-org/repo", "", nil},
+		{"allowed host and namespace", Policy{AllowedNamespaces: []string{"app.terraform.io/my-org"}}, "app.terraform.io/my-org/vpc/aws", "1.0.0", nil},
+		{"namespace on another host", Policy{AllowedNamespaces: []string{"app.terraform.io/my-org"}}, "my-org/vpc/aws", "1.0.0", []string{RuleAllowedNamespace}},
+		{"disallowed namespace", Policy{AllowedNamespaces: []string{"my-org"}}, "terraform-aws-modules/vpc/aws", "", []string{RuleAllowedNamespace}},
+		{"local modules are exempt", Policy{AllowedHosts: []string{"github.com"}, AllowedNamespaces: []string{"my-org"}}, "./modules/vpc", "", nil},
+		{"several violations", Policy{RequireRegistryVersion: true, AllowedHosts: []string{"github.com"}}, "hashicorp/consul/aws", "", []string{RuleRegistryVersion, RuleAllowedHost}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &sbom.SBOM{Modules: []sbom.ModuleInfo{{
+				Name:     "example",
+				Source:   tt.source,
+				Version:  tt.version,
+				Location: "Module call at main.tf:12",
+				Filename: "/project/main.tf",
+			}}}
+
+			violations := Check(&tt.policy, s)
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Check() = %+v, want rules %v", violations, tt.want)
+			}
+			for i, rule := range tt.want {
+				if violations[i].Rule != rule {
+					t.Errorf("violations[%d].Rule = %q, want %q", i, violations[i].Rule, rule)
+				}
+				if violations[i].Filename != "/project/main.tf" || violations[i].Line != 12 {
+					t.Errorf("violations[%d] at %s:%d, want /project/main.tf:12", i, violations[i].Filename, violations[i].Line)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package policy
+
+import (
+	"fmt"
+	"path"
+
+	"github.com/hashicorp/hcl/v2/hclsimple"
+)
+
+// Policy holds the rules that module calls are checked against. It is read from an
+// HCL file, or a JSON file using the same attribute names.
+type Policy struct {
+	// RequireRegistryVersion requires registry modules to declare a version
+	RequireRegistryVersion bool `hcl:"require_registry_version,optional"`
+	// RequirePinnedGitRef requires git sources to select a tag or full commit SHA with ?ref=
+	RequirePinnedGitRef bool `hcl:"require_pinned_git_ref,optional"`
+	// DeniedGitRefs lists refs that git sources may not select, such as "main"
+	DeniedGitRefs []string `hcl:"denied_git_refs,optional"`
+	// AllowedHosts lists the source hosts modules may come from. Entries may use
+	// path.Match wildcards such as "*.example.com". Empty allows every host.
+	AllowedHosts []string `hcl:"allowed_hosts,optional"`
+	// AllowedNamespaces lists the namespaces, organizations or buckets modules may
+	// come from, either alone or prefixed by their host. Empty allows every namespace.
+	AllowedNamespaces []string `hcl:"allowed_namespaces,optional"`
+}
+
+// Load reads a policy file
+func Load(filename string) (*Policy, error) {
+	var p Policy
+	if err := hclsimple.DecodeFile(filename, nil, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy %s: %w", filename, err)
+	}
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", filename, err)
+	}
+	return &p, nil
+}
+
+// validate checks that every pattern in the policy is well formed
+func (p *Policy) validate() error {
+	for _, pattern := range append(append([]string{}, p.AllowedHosts...), p.AllowedNamespaces...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether any of the values matches one of the patterns
+func matchAny(patterns []string, values ...string) bool {
+	for _, pattern := range patterns {
+		for _, value := range values {
+			if matched, _ := path.Match(pattern, value); matched {
+				return true
+			}
+		}
+	}
+	return false
+}
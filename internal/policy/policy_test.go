@@ -0,0 +1,73 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	write := func(name, content string) string {
+		t.Helper()
+		path := filepath.Join(tmpDir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		return path
+	}
+
+	t.Run("HCL policy", func(t *testing.T) {
+		p, err := Load(write("policy.hcl", `
+require_registry_version = true
+require_pinned_git_ref   = true
+denied_git_refs          = ["main", "master"]
+allowed_hosts            = ["registry.terraform.io", "*.example.com"]
+`))
+		if err != nil {
+			t.Fatalf("Load() = %v, want nil", err)
+		}
+		if !p.RequireRegistryVersion || !p.RequirePinnedGitRef {
+			t.Errorf("Load() = %+v, want both requirements enabled", p)
+		}
+		if !slices.Equal(p.DeniedGitRefs, []string{"main", "master"}) {
+			t.Errorf("DeniedGitRefs = %v, want [main master]", p.DeniedGitRefs)
+		}
+		if len(p.AllowedHosts) != 2 || len(p.AllowedNamespaces) != 0 {
+			t.Errorf("Load() = %+v, want two allowed hosts and no namespaces", p)
+		}
+	})
+
+	t.Run("JSON policy", func(t *testing.T) {
+		p, err := Load(write("policy.json", `{"allowed_namespaces": ["my-org"]}`))
+		if err != nil {
+			t.Fatalf("Load() = %v, want nil", err)
+		}
+		if !slices.Equal(p.AllowedNamespaces, []string{"my-org"}) || p.RequireRegistryVersion {
+			t.Errorf("Load() = %+v, want only the allowed namespace", p)
+		}
+	})
+
+	t.Run("unknown attribute", func(t *testing.T) {
+		_, err := Load(write("unknown.hcl", `require_everything = true`))
+		if err == nil || !strings.Contains(err.Error(), "failed to parse policy") {
+			t.Errorf("Load() = %v, want 'failed to parse policy'", err)
+		}
+	})
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := Load(write("pattern.hcl", `allowed_hosts = ["[example.com"]`))
+		if err == nil || !strings.Contains(err.Error(), "invalid policy") {
+			t.Errorf("Load() = %v, want 'invalid policy'", err)
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := Load(filepath.Join(tmpDir, "missing.hcl")); err == nil {
+			t.Error("Load() = nil, want error for a missing file")
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package sbom
+
+import (
+	"path/filepath"
+)
+
+// Configuration kinds
+const (
+	// ConfigurationRoot is a directory Terraform is run in, recognised by a backend,
+	// cloud or provider block
+	ConfigurationRoot = "root"
+	// ConfigurationModule is a reusable module meant to be called from another configuration
+	ConfigurationModule = "module"
+)
+
+// collectConfigurations lists each scanned directory with the module calls whose root
+// it is. With transitive scanning, the calls made by modules reached from a root are
+// listed under that root rather than under the module's own directory.
+func collectConfigurations(base string, dirs []string, loaded map[string]*loadedDirectory, modules []ModuleInfo) []Configuration {
+	byRoot := make(map[string][]ModuleInfo)
+	for _, module := range modules {
+		byRoot[module.Root] = append(byRoot[module.Root], module)
+	}
+
+	configurations := make([]Configuration, 0, len(dirs))
+	for _, dir := range dirs {
+		path, err := filepath.Rel(base, dir)
+		if err != nil {
+			path = dir
+		}
+
+		kind := ConfigurationModule
+		if l, ok := loaded[dir]; ok && l.root {
+			kind = ConfigurationRoot
+		}
+
+		configurations = append(configurations, Configuration{
+			Path:    filepath.ToSlash(path),
+			Kind:    kind,
+			Modules: byRoot[dir],
+		})
+	}
+	return configurations
+}
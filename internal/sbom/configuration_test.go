@@ -0,0 +1,129 @@
+package sbom
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerateConfigurations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_configurations_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"live/prod/main.tf": `
+terraform {
+  backend "s3" {}
+}
+
+module "network" {
+  source = "../../modules/network"
+}
+
+module "dns" {
+  source  = "terraform-aws-modules/route53/aws"
+  version = "2.10.0"
+}
+`,
+		"live/dev/main.tf": `
+provider "aws" {
+  region = "eu-west-1"
+}
+
+module "network" {
+  source = "../../modules/network"
+}
+`,
+		"live/hcp/main.tf": `
+terraform {
+  cloud {
+    organization = "example"
+  }
+}
+`,
+		"modules/network/main.tf": `
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+`,
+	})
+
+	type configuration struct {
+		path    string
+		kind    string
+		modules []string
+	}
+
+	tests := []struct {
+		name       string
+		transitive bool
+		expected   []configuration
+	}{
+		{
+			name: "each directory lists its own calls",
+			expected: []configuration{
+				{"live/dev", ConfigurationRoot, []string{"module.network"}},
+				{"live/hcp", ConfigurationRoot, nil},
+				{"live/prod", ConfigurationRoot, []string{"module.network", "module.dns"}},
+				{"modules/network", ConfigurationModule, []string{"module.vpc"}},
+			},
+		},
+		{
+			name:       "transitive calls are listed under their root",
+			transitive: true,
+			expected: []configuration{
+				{"live/dev", ConfigurationRoot, []string{"module.network", "module.network.module.vpc"}},
+				{"live/hcp", ConfigurationRoot, nil},
+				{"live/prod", ConfigurationRoot, []string{"module.network", "module.dns", "module.network.module.vpc"}},
+				{"modules/network", ConfigurationModule, nil},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Transitive: tt.transitive, Reproducible: true})
+			if err != nil {
+				t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+			}
+
+			if len(result.Configurations) != len(tt.expected) {
+				t.Fatalf("len(result.Configurations) = %v, want %v", len(result.Configurations), len(tt.expected))
+			}
+			for i, want := range tt.expected {
+				got := result.Configurations[i]
+				if got.Path != want.path || got.Kind != want.kind {
+					t.Errorf("Configurations[%d] = %s (%s), want %s (%s)", i, got.Path, got.Kind, want.path, want.kind)
+				}
+				var paths []string
+				for _, module := range got.Modules {
+					paths = append(paths, module.Path)
+					if module.Root != want.path {
+						t.Errorf("%s: %s.Root = %q, want %q", want.path, module.Path, module.Root, want.path)
+					}
+				}
+				if len(paths) != len(want.modules) {
+					t.Errorf("%s modules = %v, want %v", want.path, paths, want.modules)
+					continue
+				}
+				for j := range paths {
+					if paths[j] != want.modules[j] {
+						t.Errorf("%s modules = %v, want %v", want.path, paths, want.modules)
+						break
+					}
+				}
+			}
+		})
+	}
+}
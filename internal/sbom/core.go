@@ -0,0 +1,49 @@
+package sbom
+
+import (
+	"strings"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// Constraint joins the directory's required_version constraints into a single constraint string
+func (c TerraformCoreInfo) Constraint() string {
+	return strings.Join(c.Constraints, ", ")
+}
+
+// collectCoreRequirement returns the required_version constraints of a loaded module,
+// or false when the module does not declare any
+func collectCoreRequirement(moduleDir string, module *tfconfig.Module) (TerraformCoreInfo, bool) {
+	if len(module.RequiredCore) == 0 {
+		return TerraformCoreInfo{}, false
+	}
+	return TerraformCoreInfo{Directory: moduleDir, Constraints: module.RequiredCore}, true
+}
+
+// findCoreConflicts reports each pair of directories whose required_version constraints
+// have no version in common. Constraints that cannot be parsed are not compared.
+func findCoreConflicts(requirements []TerraformCoreInfo) []CoreConflict {
+	ranges := make([]VersionRange, len(requirements))
+	valid := make([]bool, len(requirements))
+	for i, requirement := range requirements {
+		r, err := ParseConstraint(requirement.Constraint())
+		ranges[i], valid[i] = r, err == nil
+	}
+
+	// Ranges are intervals, so constraints that agree pairwise can all be satisfied at once
+	var conflicts []CoreConflict
+	for i := range requirements {
+		for j := i + 1; j < len(requirements); j++ {
+			if !valid[i] || !valid[j] || !ranges[i].Intersect(ranges[j]).IsEmpty() {
+				continue
+			}
+			conflicts = append(conflicts, CoreConflict{
+				Directory:             requirements[i].Directory,
+				Constraint:            requirements[i].Constraint(),
+				ConflictingDirectory:  requirements[j].Directory,
+				ConflictingConstraint: requirements[j].Constraint(),
+			})
+		}
+	}
+	return conflicts
+}
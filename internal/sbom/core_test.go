@@ -0,0 +1,77 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFindCoreConflicts(t *testing.T) {
+	requirements := []TerraformCoreInfo{
+		{Directory: "/project/a", Constraints: []string{">= 1.9"}},
+		{Directory: "/project/b", Constraints: []string{"~> 1.5.0"}},
+		{Directory: "/project/c", Constraints: []string{">= 1.5", "< 2.0"}},
+		{Directory: "/project/d", Constraints: []string{"not a constraint"}},
+	}
+
+	expected := []CoreConflict{
+		{Directory: "/project/a", Constraint: ">= 1.9", ConflictingDirectory: "/project/b", ConflictingConstraint: "~> 1.5.0"},
+	}
+	if result := findCoreConflicts(requirements); !reflect.DeepEqual(result, expected) {
+		t.Errorf("findCoreConflicts() = %+v, want %+v", result, expected)
+	}
+}
+
+func TestGenerateTerraformCore(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_core_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"network/main.tf": `
+terraform {
+  required_version = ">= 1.9"
+}
+`,
+		"legacy/main.tf": `
+terraform {
+  required_version = "~> 1.5.0"
+}
+`,
+		"app/main.tf": `
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+`,
+	})
+
+	absDir, _ := filepath.Abs(tmpDir)
+
+	result, err := GenerateWithOptions(tmpDir, Options{Recursive: true})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+	}
+
+	expectedCore := []TerraformCoreInfo{
+		{Directory: filepath.Join(absDir, "legacy"), Constraints: []string{"~> 1.5.0"}},
+		{Directory: filepath.Join(absDir, "network"), Constraints: []string{">= 1.9"}},
+	}
+	if !reflect.DeepEqual(result.TerraformCore, expectedCore) {
+		t.Errorf("TerraformCore = %+v, want %+v", result.TerraformCore, expectedCore)
+	}
+
+	expectedConflicts := []CoreConflict{
+		{
+			Directory:             filepath.Join(absDir, "legacy"),
+			Constraint:            "~> 1.5.0",
+			ConflictingDirectory:  filepath.Join(absDir, "network"),
+			ConflictingConstraint: ">= 1.9",
+		},
+	}
+	if !reflect.DeepEqual(result.CoreConflicts, expectedConflicts) {
+		t.Errorf("CoreConflicts = %+v, want %+v", result.CoreConflicts, expectedConflicts)
+	}
+}
@@ -0,0 +1,48 @@
+package sbom
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// Diagnostic severities
+const (
+	DiagnosticError   = "error"
+	DiagnosticWarning = "warning"
+)
+
+// String formats the diagnostic with the most precise position available
+func (d Diagnostic) String() string {
+	switch {
+	case d.Filename != "" && d.Line > 0:
+		return fmt.Sprintf("%s:%d: %s", d.Filename, d.Line, d.Message)
+	case d.Filename != "":
+		return fmt.Sprintf("%s: %s", d.Filename, d.Message)
+	}
+	return fmt.Sprintf("%s: %s", d.Directory, d.Message)
+}
+
+// configDiagnostics converts the diagnostics reported while loading the module in dir
+func configDiagnostics(dir string, diags tfconfig.Diagnostics) []Diagnostic {
+	result := make([]Diagnostic, 0, len(diags))
+	for _, diag := range diags {
+		d := Diagnostic{
+			Severity:  DiagnosticWarning,
+			Directory: dir,
+			Message:   diag.Summary,
+		}
+		if diag.Severity == tfconfig.DiagError {
+			d.Severity = DiagnosticError
+		}
+		if diag.Detail != "" {
+			d.Message += ": " + diag.Detail
+		}
+		if diag.Pos != nil {
+			d.Filename = diag.Pos.Filename
+			d.Line = diag.Pos.Line
+		}
+		result = append(result, d)
+	}
+	return result
+}
@@ -0,0 +1,99 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		name       string
+		diagnostic Diagnostic
+		expected   string
+	}{
+		{"file and line", Diagnostic{Directory: "/project", Filename: "/project/main.tf", Line: 3, Message: "Unclosed configuration block"}, "/project/main.tf:3: Unclosed configuration block"},
+		{"file only", Diagnostic{Directory: "/project", Filename: "/project/.terraform.lock.hcl", Message: "failed to parse lock file"}, "/project/.terraform.lock.hcl: failed to parse lock file"},
+		{"directory only", Diagnostic{Directory: "/project", Message: "failed to read module manifest"}, "/project: failed to read module manifest"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := test.diagnostic.String(); result != test.expected {
+				t.Errorf("String() = %q, want %q", result, test.expected)
+			}
+		})
+	}
+}
+
+func TestGenerateContinuesPastBrokenDirectories(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_diagnostics_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"app/main.tf": `
+module "vpc" {
+  source = "terraform-aws-modules/vpc/aws"
+}
+
+module "broken_child" {
+  source = "../examples/broken"
+}
+`,
+		"examples/broken/main.tf": `
+module "broken" {
+  source = "./x"
+`,
+		"network/main.tf": `
+module "subnets" {
+  source = "hashicorp/subnets/cidr"
+}
+`,
+	})
+
+	absDir, _ := filepath.Abs(tmpDir)
+	brokenDir := filepath.Join(absDir, "examples", "broken")
+
+	t.Run("records diagnostics and keeps other directories", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Transitive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		names := make(map[string]bool)
+		for _, module := range result.Modules {
+			names[module.Name] = true
+		}
+		for _, name := range []string{"vpc", "broken_child", "subnets"} {
+			if !names[name] {
+				t.Errorf("module %s missing from %+v", name, result.Modules)
+			}
+		}
+
+		// The broken directory is reached both as a module call and by the recursive
+		// scan, but only reported once
+		var errors int
+		for _, diagnostic := range result.Diagnostics {
+			if diagnostic.Severity != DiagnosticError {
+				continue
+			}
+			errors++
+			if diagnostic.Directory != brokenDir || diagnostic.Filename != filepath.Join(brokenDir, "main.tf") || diagnostic.Line == 0 {
+				t.Errorf("diagnostic = %+v, want an error in %s/main.tf", diagnostic, brokenDir)
+			}
+		}
+		if errors != 1 {
+			t.Errorf("error diagnostics = %d, want 1 in %+v", errors, result.Diagnostics)
+		}
+	})
+
+	t.Run("strict mode fails fast", func(t *testing.T) {
+		_, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Strict: true})
+		if err == nil {
+			t.Error("GenerateWithOptions() = nil, want error in strict mode")
+		}
+	})
+}
@@ -68,3 +68,18 @@ func FindTerraformModules(root string, recursive bool) ([]string, error) {
 	})
 	return modules, err
 }
+
+// nearestAncestor returns the closest directory at or above dir, but not above root,
+// for which match reports true, or "" if there is none
+func nearestAncestor(root, dir string, match func(dir string) bool) string {
+	for {
+		if match(dir) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if dir == root || parent == dir || !strings.HasPrefix(parent, root) {
+			return ""
+		}
+		dir = parent
+	}
+}
@@ -3,13 +3,44 @@ package sbom
 import (
 	"fmt"
 	"path/filepath"
-	"time"
+	"slices"
+	"sort"
 
 	"github.com/hashicorp/terraform-config-inspect/tfconfig"
 )
 
+// Options controls how an SBOM is generated
+type Options struct {
+	// Recursive scans every directory below the configuration path
+	Recursive bool
+	// ResolveInstalled replaces module version constraints with the versions recorded
+	// in .terraform/modules/modules.json by terraform init
+	ResolveInstalled bool
+	// Transitive follows local and installed module calls into the called module and
+	// records its module calls as children
+	Transitive bool
+	// Resources records the managed resources and data sources of each loaded
+	// configuration and counts them per provider
+	Resources bool
+	// Strict aborts the scan at the first directory that fails to load instead of
+	// recording the failure as a diagnostic and skipping the directory
+	Strict bool
+	// Jobs is the number of directories parsed concurrently. Zero uses one worker
+	// per CPU.
+	Jobs int
+	// Reproducible records paths relative to the configuration path and, unless
+	// SOURCE_DATE_EPOCH is set, a fixed timestamp so that the same configuration
+	// always yields the same SBOM
+	Reproducible bool
+}
+
 // Generate generates a Software Bill of Materials for a Terraform configuration
 func Generate(configPath string, recursive bool) (*SBOM, error) {
+	return GenerateWithOptions(configPath, Options{Recursive: recursive})
+}
+
+// GenerateWithOptions generates a Software Bill of Materials for a Terraform configuration
+func GenerateWithOptions(configPath string, opts Options) (*SBOM, error) {
 	// Validate the configuration path exists
 	if err := ValidateTerraformDirectory(configPath); err != nil {
 		return nil, err
@@ -22,38 +53,301 @@ func Generate(configPath string, recursive bool) (*SBOM, error) {
 	}
 
 	// Find all Terraform module directories
-	moduleDirs, err := FindTerraformModules(absPath, recursive)
+	moduleDirs, err := FindTerraformModules(absPath, opts.Recursive)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find Terraform modules: %w", err)
 	}
 
+	generated, err := generatedTimestamp(opts.Reproducible)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create SBOM with initial structure
 	sbom := &SBOM{
 		Version:   "1.0",
-		Generated: time.Now().Format(time.RFC3339),
+		Generated: generated,
 		Tool:      "terraform-sbom",
 		Modules:   []ModuleInfo{},
 	}
 
-	// Process each directory and collect all modules
-	for _, moduleDir := range moduleDirs {
-		module, diags := tfconfig.LoadModule(moduleDir)
-		if diags.HasErrors() {
-			return nil, fmt.Errorf("failed to load Terraform module from %s: %s", moduleDir, diags.Error())
-		}
+	walker := &moduleWalker{
+		opts:     opts,
+		sbom:     sbom,
+		loaded:   make(map[string]bool),
+		children: make(map[string]bool),
+		reported: make(map[string]bool),
+		// Parse every directory up front; the walk below then runs in directory order
+		dirs: loadDirectories(moduleDirs, opts.Jobs),
+	}
+	if opts.ResolveInstalled || opts.Transitive {
+		walker.resolver = newModuleResolver(absPath)
+	}
 
-		// Convert each module call to ModuleInfo
-		for _, moduleCall := range module.ModuleCalls {
-			moduleInfo := ModuleInfo{
-				Name:     moduleCall.Name,
-				Source:   moduleCall.Source,
-				Version:  moduleCall.Version,
-				Location: fmt.Sprintf("Module call at %s:%d", moduleCall.Pos.Filename, moduleCall.Pos.Line),
-				Filename: moduleCall.Pos.Filename,
+	// Directories called from another scanned directory are part of their caller's tree,
+	// whatever order the directories are visited in
+	called := make(map[string]bool)
+	if opts.Transitive {
+		called = walker.calledDirectories(moduleDirs)
+	}
+
+	// Process each directory and collect all modules. Called directories that were not
+	// reached from a root, because they only call each other, are walked last.
+	for _, pass := range []bool{false, true} {
+		for _, moduleDir := range moduleDirs {
+			// Directories already reached through a module call are part of that tree
+			if called[moduleDir] != pass || walker.children[moduleDir] {
+				continue
+			}
+
+			module, err := walker.load(moduleDir)
+			if err != nil {
+				return nil, err
+			}
+			if module == nil {
+				continue
+			}
+
+			walker.collect(moduleDir, module)
+			if err := walker.walk(moduleDir, moduleDir, module, "", 1, []string{moduleDir}); err != nil {
+				return nil, err
 			}
-			sbom.Modules = append(sbom.Modules, moduleInfo)
 		}
 	}
 
+	// Attach exact provider versions from any dependency lock files
+	lockIssues, err := applyLockFiles(absPath, moduleDirs, sbom.Providers, walker.fail)
+	if err != nil {
+		return nil, err
+	}
+	sbom.LockIssues = lockIssues
+
+	sbom.CoreConflicts = findCoreConflicts(sbom.TerraformCore)
+
+	if opts.Resources {
+		sbom.ResourceSummary = summarizeResources(sbom.Resources)
+	}
+
+	setFilePaths(sbom, absPath)
+	sbom.Configurations = collectConfigurations(absPath, moduleDirs, walker.dirs, sbom.Modules)
+	if opts.Reproducible {
+		relativizePaths(sbom, absPath)
+	}
+	sortModules(sbom.Modules)
+	for i := range sbom.Configurations {
+		sortModules(sbom.Configurations[i].Modules)
+	}
+
 	return sbom, nil
 }
+
+// moduleWalker converts the module calls of a configuration into ModuleInfo entries,
+// following each call into the module it loads when transitive scanning is enabled
+type moduleWalker struct {
+	opts     Options
+	resolver *moduleResolver
+	sbom     *SBOM
+	loaded   map[string]bool
+	children map[string]bool
+	reported map[string]bool
+	dirs     map[string]*loadedDirectory
+}
+
+// load returns the module in dir, parsing it if it was not loaded up front, and
+// records its diagnostics. A module that fails to load is returned as nil, or as an
+// error in strict mode.
+func (w *moduleWalker) load(dir string) (*tfconfig.Module, error) {
+	loaded, ok := w.dirs[dir]
+	if !ok {
+		loaded = loadDirectory(dir)
+		w.dirs[dir] = loaded
+	}
+
+	module, diags := loaded.module, loaded.diags
+	if diags.HasErrors() && w.opts.Strict {
+		return nil, fmt.Errorf("failed to load Terraform module from %s: %s", dir, diags.Error())
+	}
+
+	// A module called from several places is only reported once
+	if !w.reported[dir] {
+		w.reported[dir] = true
+		w.sbom.Diagnostics = append(w.sbom.Diagnostics, configDiagnostics(dir, diags)...)
+	}
+	if diags.HasErrors() {
+		return nil, nil
+	}
+	return module, nil
+}
+
+// calledDirectories returns the directories in dirs that another directory in dirs
+// reaches through a chain of local module calls. Directories are parsed as needed
+// without recording diagnostics, which load reports when the directory is walked.
+func (w *moduleWalker) calledDirectories(dirs []string) map[string]bool {
+	called := make(map[string]bool)
+	for _, dir := range dirs {
+		seen := map[string]bool{dir: true}
+		pending := []string{dir}
+		for len(pending) > 0 {
+			current := pending[len(pending)-1]
+			pending = pending[:len(pending)-1]
+			for _, childDir := range w.localCalls(current) {
+				if seen[childDir] {
+					continue
+				}
+				seen[childDir] = true
+				called[childDir] = true
+				pending = append(pending, childDir)
+			}
+		}
+	}
+	return called
+}
+
+// localCalls returns the module directories that the local module calls of dir load
+func (w *moduleWalker) localCalls(dir string) []string {
+	loaded, ok := w.dirs[dir]
+	if !ok {
+		loaded = loadDirectory(dir)
+		w.dirs[dir] = loaded
+	}
+	if loaded.diags.HasErrors() {
+		return nil
+	}
+
+	var dirs []string
+	for _, moduleCall := range loaded.module.ModuleCalls {
+		if ParseSource(moduleCall.Source).Kind != SourceLocal {
+			continue
+		}
+		childDir := filepath.Join(dir, moduleCall.Source)
+		if tfconfig.IsModuleDir(childDir) {
+			dirs = append(dirs, childDir)
+		}
+	}
+	return dirs
+}
+
+// fail records err as an error diagnostic for dir so that the scan can continue, or
+// returns it in strict mode
+func (w *moduleWalker) fail(dir, filename string, err error) error {
+	if w.opts.Strict {
+		return err
+	}
+	w.sbom.Diagnostics = append(w.sbom.Diagnostics, Diagnostic{
+		Severity:  DiagnosticError,
+		Directory: dir,
+		Filename:  filename,
+		Message:   err.Error(),
+	})
+	return nil
+}
+
+// collect records the providers, required Terraform version and, when requested, the
+// resources of a module directory the first time it is loaded
+func (w *moduleWalker) collect(moduleDir string, module *tfconfig.Module) {
+	if w.loaded[moduleDir] {
+		return
+	}
+	w.loaded[moduleDir] = true
+	w.sbom.Providers = append(w.sbom.Providers, w.dirs[moduleDir].providers...)
+	if core, ok := collectCoreRequirement(moduleDir, module); ok {
+		w.sbom.TerraformCore = append(w.sbom.TerraformCore, core)
+	}
+	if w.opts.Resources {
+		w.sbom.Resources = append(w.sbom.Resources, collectResources(moduleDir, module)...)
+	}
+}
+
+// walk records the module calls made by module, which was loaded from moduleDir.
+// parent is the address of the call that loaded module, empty for a root
+// configuration, and chain holds the directories on the path from root so that
+// cyclic local module references are not followed forever.
+func (w *moduleWalker) walk(root, moduleDir string, module *tfconfig.Module, parent string, depth int, chain []string) error {
+	names := make([]string, 0, len(module.ModuleCalls))
+	for name := range module.ModuleCalls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		moduleCall := module.ModuleCalls[name]
+		parsedSource := ParseSource(moduleCall.Source)
+
+		path := "module." + moduleCall.Name
+		if parent != "" {
+			path = parent + "." + path
+		}
+
+		moduleInfo := ModuleInfo{
+			Name:         moduleCall.Name,
+			Source:       moduleCall.Source,
+			Version:      moduleCall.Version,
+			Location:     fmt.Sprintf("Module call at %s:%d", moduleCall.Pos.Filename, moduleCall.Pos.Line),
+			Filename:     moduleCall.Pos.Filename,
+			Line:         moduleCall.Pos.Line,
+			ParsedSource: &parsedSource,
+			Root:         root,
+			Path:         path,
+			Parent:       parent,
+			Depth:        depth,
+		}
+		// tfconfig only records the line, so take the column from the module block
+		if rng, ok := w.dirs[moduleDir].modules[moduleCall.Name]; ok {
+			moduleInfo.Line, moduleInfo.Column = rng.Start.Line, rng.Start.Column
+		}
+
+		var installed ModuleManifestEntry
+		var isInstalled bool
+		if w.resolver != nil && parsedSource.Kind != SourceLocal {
+			var err error
+			installed, isInstalled, err = w.resolver.resolve(moduleDir, moduleCall.Name)
+			if err != nil {
+				if err := w.fail(moduleDir, "", err); err != nil {
+					return err
+				}
+			}
+		}
+		if isInstalled && w.opts.ResolveInstalled {
+			moduleInfo.VersionConstraint = moduleInfo.Version
+			if installed.Version != "" {
+				moduleInfo.Version = installed.Version
+			}
+			moduleInfo.InstalledDir = installed.Dir
+		}
+
+		moduleInfo.PURL = PackageURL(moduleInfo.Source, moduleInfo.Version)
+		w.sbom.Modules = append(w.sbom.Modules, moduleInfo)
+
+		if !w.opts.Transitive {
+			continue
+		}
+
+		// Only local modules and modules installed by terraform init can be followed
+		var childDir string
+		switch {
+		case parsedSource.Kind == SourceLocal:
+			childDir = filepath.Join(moduleDir, moduleCall.Source)
+		case isInstalled:
+			childDir = installed.Dir
+		}
+		if childDir == "" || slices.Contains(chain, childDir) || !tfconfig.IsModuleDir(childDir) {
+			continue
+		}
+
+		child, err := w.load(childDir)
+		if err != nil {
+			return err
+		}
+		if child == nil {
+			continue
+		}
+
+		w.children[childDir] = true
+		w.collect(childDir, child)
+		if err := w.walk(root, childDir, child, path, depth+1, append(chain, childDir)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
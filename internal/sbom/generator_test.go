@@ -1,8 +1,10 @@
 package sbom
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -199,6 +201,24 @@ module "security_group" {
 		if !moduleNames["security_group"] {
 			t.Error("Expected security_group module not found")
 		}
+
+		// Verify package URLs are derived from source and version
+		for _, module := range result.Modules {
+			if module.Name == "vpc" && module.PURL != "pkg:terraform/terraform-aws-modules/vpc?provider=aws" {
+				t.Errorf("vpc.PURL = %q, want 'pkg:terraform/terraform-aws-modules/vpc?provider=aws'", module.PURL)
+			}
+		}
+
+		// Verify every module carries its parsed source
+		for _, module := range result.Modules {
+			if module.ParsedSource == nil {
+				t.Errorf("%s.ParsedSource = nil, want parsed source", module.Name)
+				continue
+			}
+			if module.Name == "vpc" && module.ParsedSource.Kind != SourceRegistry {
+				t.Errorf("vpc.ParsedSource.Kind = %v, want %v", module.ParsedSource.Kind, SourceRegistry)
+			}
+		}
 	})
 
 	// Test with invalid Terraform configuration
@@ -222,9 +242,20 @@ module "broken" {
 			t.Fatalf("failed to write config file: %v", err)
 		}
 
-		_, err = Generate(tmpDir, false)
+		result, err := Generate(tmpDir, false)
+		if err != nil {
+			t.Fatalf("Generate() = %v, want nil", err)
+		}
+		if len(result.Diagnostics) == 0 || result.Diagnostics[0].Severity != DiagnosticError {
+			t.Fatalf("Diagnostics = %+v, want an error diagnostic", result.Diagnostics)
+		}
+		if result.Diagnostics[0].Filename != configPath || result.Diagnostics[0].Line == 0 {
+			t.Errorf("Diagnostics[0] = %+v, want a position in %s", result.Diagnostics[0], configPath)
+		}
+
+		_, err = GenerateWithOptions(tmpDir, Options{Strict: true})
 		if err == nil {
-			t.Error("Generate() = nil, want error for invalid configuration")
+			t.Error("GenerateWithOptions() = nil, want error for invalid configuration in strict mode")
 		}
 	})
 
@@ -951,3 +982,94 @@ module "outputs_module" {
 		}
 	})
 }
+
+// writeSyntheticTree creates count configuration directories below dir, each with
+// module calls, providers, resources and a local child module
+func writeSyntheticTree(tb testing.TB, dir string, count int) {
+	tb.Helper()
+	files := make(map[string]string, count*3)
+	for i := 0; i < count; i++ {
+		stack := fmt.Sprintf("stacks/stack-%04d", i)
+		files[stack+"/versions.tf"] = `
+terraform {
+  required_version = ">= 1.5"
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+  }
+}
+`
+		files[stack+"/main.tf"] = fmt.Sprintf(`
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.%d.0"
+}
+
+module "app" {
+  source = "./modules/app"
+}
+
+resource "aws_s3_bucket" "logs" {}
+`, i%10)
+		files[stack+"/modules/app/main.tf"] = `
+module "sg" {
+  source = "git::https://example.com/sg.git?ref=v1.0.0"
+}
+
+data "aws_caller_identity" "current" {}
+`
+	}
+	writeFiles(tb, dir, files)
+}
+
+func TestGenerateParallelIsDeterministic(t *testing.T) {
+	tmpDir := t.TempDir()
+	writeSyntheticTree(t, tmpDir, 20)
+
+	base := Options{Recursive: true, Transitive: true, Resources: true}
+
+	sequential := base
+	sequential.Jobs = 1
+	expected, err := GenerateWithOptions(tmpDir, sequential)
+	if err != nil {
+		t.Fatalf("GenerateWithOptions(Jobs=1) = %v, want nil", err)
+	}
+	if len(expected.Modules) != 60 {
+		t.Fatalf("len(Modules) = %v, want 60", len(expected.Modules))
+	}
+
+	for _, jobs := range []int{0, 4, 64} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			opts := base
+			opts.Jobs = jobs
+			result, err := GenerateWithOptions(tmpDir, opts)
+			if err != nil {
+				t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+			}
+
+			result.Generated = expected.Generated
+			if !reflect.DeepEqual(result, expected) {
+				t.Errorf("GenerateWithOptions(Jobs=%d) differs from the sequential scan", jobs)
+			}
+		})
+	}
+}
+
+func BenchmarkGenerate(b *testing.B) {
+	tmpDir := b.TempDir()
+	writeSyntheticTree(b, tmpDir, 200)
+
+	// The speedup over jobs=1 is bounded by the number of CPUs available
+	for _, jobs := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprintf("jobs=%d", jobs), func(b *testing.B) {
+			opts := Options{Recursive: true, Jobs: jobs}
+			for b.Loop() {
+				if _, err := GenerateWithOptions(tmpDir, opts); err != nil {
+					b.Fatalf("GenerateWithOptions() = %v, want nil", err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package sbom
+
+import (
+	"runtime"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// loadedDirectory is the result of parsing one configuration directory
+type loadedDirectory struct {
+	module    *tfconfig.Module
+	diags     tfconfig.Diagnostics
+	providers []ProviderInfo
+	// modules holds the position of each module block by call name
+	modules map[string]hcl.Range
+	// root reports whether the directory is a root configuration rather than a module
+	root bool
+}
+
+// loadDirectory parses the configuration in dir and, when it loads without errors,
+// its provider requirements and the positions of its module calls
+func loadDirectory(dir string) *loadedDirectory {
+	module, diags := tfconfig.LoadModule(dir)
+	loaded := &loadedDirectory{module: module, diags: diags}
+	if !diags.HasErrors() {
+		decls := parseDeclarations(dir)
+		loaded.providers = collectProviders(dir, module, decls.providers)
+		loaded.modules = decls.modules
+		loaded.root = decls.root
+	}
+	return loaded
+}
+
+// loadDirectories parses dirs using up to jobs concurrent workers, or one per CPU when
+// jobs is not positive. Results are keyed by directory so that the order in which
+// workers finish cannot affect the SBOM.
+func loadDirectories(dirs []string, jobs int) map[string]*loadedDirectory {
+	if jobs < 1 {
+		jobs = runtime.NumCPU()
+	}
+	if jobs > len(dirs) {
+		jobs = len(dirs)
+	}
+
+	results := make([]*loadedDirectory, len(dirs))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = loadDirectory(dirs[i])
+			}
+		}()
+	}
+	for i := range dirs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	loaded := make(map[string]*loadedDirectory, len(dirs))
+	for i, dir := range dirs {
+		loaded[dir] = results[i]
+	}
+	return loaded
+}
@@ -0,0 +1,71 @@
+package sbom
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// StartLine returns the line of the module block, falling back to the legacy Location
+// of SBOMs written before Line was recorded
+func (m ModuleInfo) StartLine() int {
+	if m.Line > 0 {
+		return m.Line
+	}
+	return locationLine(m.Location)
+}
+
+// StartLine returns the line declaring the provider, falling back to the legacy Location
+// of SBOMs written before Line was recorded
+func (p ProviderInfo) StartLine() int {
+	if p.Line > 0 {
+		return p.Line
+	}
+	return locationLine(p.Location)
+}
+
+// StartLine returns the line of the affected component, falling back to the legacy
+// Location of SBOMs written before Line was recorded
+func (v Vulnerability) StartLine() int {
+	if v.Line > 0 {
+		return v.Line
+	}
+	return locationLine(v.Location)
+}
+
+// locationLine extracts the line number from a "... at file:line" location
+func locationLine(location string) int {
+	idx := strings.LastIndex(location, ":")
+	if idx == -1 {
+		return 0
+	}
+	line, err := strconv.Atoi(location[idx+1:])
+	if err != nil {
+		return 0
+	}
+	return line
+}
+
+// setFilePaths records the file of every module call and provider declaration relative
+// to root, the directory the scan started from, using forward slashes
+func setFilePaths(s *SBOM, root string) {
+	for i := range s.Modules {
+		s.Modules[i].FilePath = relativeFilePath(root, s.Modules[i].Filename)
+	}
+	for i := range s.Providers {
+		s.Providers[i].FilePath = relativeFilePath(root, s.Providers[i].Filename)
+	}
+}
+
+// relativeFilePath returns filename relative to root, or an empty string when filename
+// is empty or lies outside root
+func relativeFilePath(root, filename string) string {
+	if filename == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(root, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return ""
+	}
+	return filepath.ToSlash(rel)
+}
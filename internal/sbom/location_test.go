@@ -0,0 +1,111 @@
+package sbom
+
+import (
+	"os"
+	"testing"
+)
+
+func TestGenerateLocations(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_locations_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"main.tf": `
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+  module "indented" {
+  source = "./modules/network"
+}
+`,
+		"modules/network/main.tf": `module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+`,
+	})
+
+	for _, reproducible := range []bool{false, true} {
+		result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Reproducible: reproducible})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		expected := map[string]struct {
+			line, column int
+			filePath     string
+		}{
+			"indented": {10, 3, "main.tf"},
+			"vpc":      {1, 1, "modules/network/main.tf"},
+		}
+		if len(result.Modules) != len(expected) {
+			t.Fatalf("len(result.Modules) = %v, want %v", len(result.Modules), len(expected))
+		}
+		for _, module := range result.Modules {
+			want := expected[module.Name]
+			if module.Line != want.line || module.Column != want.column || module.FilePath != want.filePath {
+				t.Errorf("reproducible=%v: %s at %s:%d:%d, want %s:%d:%d", reproducible, module.Name,
+					module.FilePath, module.Line, module.Column, want.filePath, want.line, want.column)
+			}
+		}
+
+		if len(result.Providers) != 1 {
+			t.Fatalf("len(result.Providers) = %v, want 1", len(result.Providers))
+		}
+		if p := result.Providers[0]; p.Line != 4 || p.Column != 5 || p.FilePath != "main.tf" {
+			t.Errorf("reproducible=%v: provider at %s:%d:%d, want main.tf:4:5", reproducible, p.FilePath, p.Line, p.Column)
+		}
+	}
+}
+
+func TestStartLine(t *testing.T) {
+	tests := []struct {
+		name   string
+		module ModuleInfo
+		want   int
+	}{
+		{"structured line", ModuleInfo{Line: 7, Location: "Module call at main.tf:3"}, 7},
+		{"legacy location", ModuleInfo{Location: "Module call at main.tf:12"}, 12},
+		{"windows path", ModuleInfo{Location: `Module call at C:\work\main.tf:5`}, 5},
+		{"no position", ModuleInfo{}, 0},
+		{"malformed location", ModuleInfo{Location: "Module call at main.tf"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.module.StartLine(); got != tt.want {
+				t.Errorf("StartLine() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelativeFilePath(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		want     string
+	}{
+		{"file in root", "/work/repo/main.tf", "main.tf"},
+		{"nested file", "/work/repo/modules/vpc/main.tf", "modules/vpc/main.tf"},
+		{"outside root", "/work/shared/main.tf", ""},
+		{"sibling with common prefix", "/work/repo-other/main.tf", ""},
+		{"empty", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := relativeFilePath("/work/repo", tt.filename); got != tt.want {
+				t.Errorf("relativeFilePath(%q) = %q, want %q", tt.filename, got, tt.want)
+			}
+		})
+	}
+}
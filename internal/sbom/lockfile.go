@@ -0,0 +1,150 @@
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+)
+
+// LockFileName is the dependency lock file written by terraform init
+const LockFileName = ".terraform.lock.hcl"
+
+// Lock issue kinds reported when required providers and the lock file disagree
+const (
+	LockIssueMissingFromLock = "missing_from_lock"
+	LockIssueNotRequired     = "not_required"
+)
+
+// LockedProvider is a provider selection recorded in a dependency lock file
+type LockedProvider struct {
+	Source      string
+	Version     string
+	Constraints string
+	Hashes      []string
+}
+
+// lockFileSchema selects the provider blocks of a dependency lock file
+var lockFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{{Type: "provider", LabelNames: []string{"source"}}},
+}
+
+// lockedProviderBlock is the body of a provider block in a dependency lock file
+type lockedProviderBlock struct {
+	Version     string   `hcl:"version"`
+	Constraints string   `hcl:"constraints,optional"`
+	Hashes      []string `hcl:"hashes,optional"`
+	Remain      hcl.Body `hcl:",remain"`
+}
+
+// ParseLockFile reads a .terraform.lock.hcl file and returns its provider selections
+// keyed by fully-qualified source address
+func ParseLockFile(path string) (map[string]LockedProvider, error) {
+	file, diags := hclparse.NewParser().ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse lock file %s: %s", path, diags.Error())
+	}
+
+	content, _, diags := file.Body.PartialContent(lockFileSchema)
+	if diags.HasErrors() {
+		return nil, fmt.Errorf("failed to parse lock file %s: %s", path, diags.Error())
+	}
+
+	locked := make(map[string]LockedProvider)
+	for _, block := range content.Blocks {
+		var body lockedProviderBlock
+		if diags := gohcl.DecodeBody(block.Body, nil, &body); diags.HasErrors() {
+			return nil, fmt.Errorf("failed to parse lock file %s: %s", path, diags.Error())
+		}
+
+		source := block.Labels[0]
+		locked[source] = LockedProvider{
+			Source:      source,
+			Version:     body.Version,
+			Constraints: body.Constraints,
+			Hashes:      body.Hashes,
+		}
+	}
+
+	return locked, nil
+}
+
+// applyLockFiles attaches locked versions and hashes to providers using the lock file
+// in the provider's directory or its nearest scanned ancestor, and returns the
+// providers that are missing from, or only present in, each lock file. Lock files
+// that cannot be parsed are passed to fail and skipped unless it returns an error.
+func applyLockFiles(root string, moduleDirs []string, providers []ProviderInfo, fail func(dir, filename string, err error) error) ([]LockIssue, error) {
+	locks := make(map[string]map[string]LockedProvider)
+	var lockDirs []string
+	for _, dir := range moduleDirs {
+		path := filepath.Join(dir, LockFileName)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		locked, err := ParseLockFile(path)
+		if err != nil {
+			if err := fail(dir, path, err); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		locks[dir] = locked
+		lockDirs = append(lockDirs, dir)
+	}
+
+	// Every source required under each lock file's directory
+	required := make(map[string]map[string]bool)
+	for i := range providers {
+		lockDir := nearestAncestor(root, providers[i].Directory, func(dir string) bool {
+			_, ok := locks[dir]
+			return ok
+		})
+		if lockDir == "" {
+			continue
+		}
+		if required[lockDir] == nil {
+			required[lockDir] = make(map[string]bool)
+		}
+		required[lockDir][providers[i].Source] = true
+
+		lockPath := filepath.Join(lockDir, LockFileName)
+		if locked, ok := locks[lockDir][providers[i].Source]; ok {
+			providers[i].Version = locked.Version
+			providers[i].Hashes = locked.Hashes
+			providers[i].LockFile = lockPath
+		}
+	}
+
+	var issues []LockIssue
+	sort.Strings(lockDirs)
+	for _, dir := range lockDirs {
+		lockPath := filepath.Join(dir, LockFileName)
+
+		sources := make([]string, 0, len(required[dir])+len(locks[dir]))
+		for source := range required[dir] {
+			sources = append(sources, source)
+		}
+		for source := range locks[dir] {
+			if !required[dir][source] {
+				sources = append(sources, source)
+			}
+		}
+		sort.Strings(sources)
+
+		for _, source := range sources {
+			_, isLocked := locks[dir][source]
+			switch {
+			case !isLocked:
+				issues = append(issues, LockIssue{LockFile: lockPath, Provider: source, Issue: LockIssueMissingFromLock})
+			case !required[dir][source]:
+				issues = append(issues, LockIssue{LockFile: lockPath, Provider: source, Issue: LockIssueNotRequired})
+			}
+		}
+	}
+
+	return issues, nil
+}
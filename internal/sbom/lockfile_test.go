@@ -0,0 +1,234 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testLockFile = `# This file is maintained automatically by "terraform init".
+# Manual edits may be lost in future updates.
+
+provider "registry.terraform.io/hashicorp/aws" {
+  version     = "5.31.0"
+  constraints = "~> 5.0"
+  hashes = [
+    "h1:ltxyuBWIy9cq0kIKDJH1jeWJy/y7XJLjS4QrsQK4plA=",
+    "zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d",
+  ]
+}
+
+provider "registry.terraform.io/hashicorp/random" {
+  version = "3.6.0"
+  hashes = [
+    "h1:R5Ucn26riKIEijcsiOMBR3uOAjuOMfI1x7XvH4P6B1w=",
+  ]
+}
+`
+
+func TestParseLockFile(t *testing.T) {
+	t.Run("valid lock file", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "test_lockfile_*")
+		if err != nil {
+			t.Fatalf("failed to create temp directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		path := filepath.Join(tmpDir, LockFileName)
+		if err := os.WriteFile(path, []byte(testLockFile), 0644); err != nil {
+			t.Fatalf("failed to write lock file: %v", err)
+		}
+
+		locked, err := ParseLockFile(path)
+		if err != nil {
+			t.Fatalf("ParseLockFile() = %v, want nil", err)
+		}
+
+		expected := map[string]LockedProvider{
+			"registry.terraform.io/hashicorp/aws": {
+				Source:      "registry.terraform.io/hashicorp/aws",
+				Version:     "5.31.0",
+				Constraints: "~> 5.0",
+				Hashes: []string{
+					"h1:ltxyuBWIy9cq0kIKDJH1jeWJy/y7XJLjS4QrsQK4plA=",
+					"zh:0cdb9c2083bf0902442384f7309367791e4640581652dda456f2d6d7abf0de8d",
+				},
+			},
+			"registry.terraform.io/hashicorp/random": {
+				Source:  "registry.terraform.io/hashicorp/random",
+				Version: "3.6.0",
+				Hashes:  []string{"h1:R5Ucn26riKIEijcsiOMBR3uOAjuOMfI1x7XvH4P6B1w="},
+			},
+		}
+		if !reflect.DeepEqual(locked, expected) {
+			t.Errorf("ParseLockFile() = %+v, want %+v", locked, expected)
+		}
+	})
+
+	t.Run("invalid syntax", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "test_lockfile_*")
+		if err != nil {
+			t.Fatalf("failed to create temp directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		path := filepath.Join(tmpDir, LockFileName)
+		if err := os.WriteFile(path, []byte(`provider "x" {`), 0644); err != nil {
+			t.Fatalf("failed to write lock file: %v", err)
+		}
+
+		_, err = ParseLockFile(path)
+		if err == nil {
+			t.Fatal("ParseLockFile() = nil, want error for invalid syntax")
+		}
+		if !strings.Contains(err.Error(), "failed to parse lock file") {
+			t.Errorf("error message = %v, want 'failed to parse lock file'", err.Error())
+		}
+	})
+
+	t.Run("missing version", func(t *testing.T) {
+		tmpDir, err := os.MkdirTemp("", "test_lockfile_*")
+		if err != nil {
+			t.Fatalf("failed to create temp directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+
+		path := filepath.Join(tmpDir, LockFileName)
+		if err := os.WriteFile(path, []byte("provider \"registry.terraform.io/hashicorp/aws\" {\n  hashes = []\n}\n"), 0644); err != nil {
+			t.Fatalf("failed to write lock file: %v", err)
+		}
+
+		if _, err := ParseLockFile(path); err == nil {
+			t.Error("ParseLockFile() = nil, want error for a provider without a version")
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := ParseLockFile(filepath.Join(os.TempDir(), "does-not-exist", LockFileName)); err == nil {
+			t.Error("ParseLockFile() = nil, want error for a missing file")
+		}
+	})
+}
+
+func TestGenerateWithLockFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_lockfile_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	root := `
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    google = {
+      source = "hashicorp/google"
+    }
+  }
+}
+`
+	child := `
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+`
+	childDir := filepath.Join(tmpDir, "modules", "network")
+	if err := os.MkdirAll(childDir, 0755); err != nil {
+		t.Fatalf("failed to create child directory: %v", err)
+	}
+	files := map[string]string{
+		filepath.Join(tmpDir, "main.tf"):       root,
+		filepath.Join(tmpDir, LockFileName):    testLockFile,
+		filepath.Join(childDir, "versions.tf"): child,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	result, err := Generate(tmpDir, true)
+	if err != nil {
+		t.Fatalf("Generate() = %v, want nil", err)
+	}
+
+	absDir, _ := filepath.Abs(tmpDir)
+	lockPath := filepath.Join(absDir, LockFileName)
+
+	// Both the root and the child module resolve aws through the root lock file
+	lockedAWS := 0
+	for _, provider := range result.Providers {
+		switch provider.Name {
+		case "aws":
+			if provider.Version != "5.31.0" {
+				t.Errorf("aws.Version = %q, want '5.31.0' (directory %s)", provider.Version, provider.Directory)
+			}
+			if len(provider.Hashes) != 2 {
+				t.Errorf("len(aws.Hashes) = %v, want 2", len(provider.Hashes))
+			}
+			if provider.LockFile != lockPath {
+				t.Errorf("aws.LockFile = %q, want %q", provider.LockFile, lockPath)
+			}
+			lockedAWS++
+		case "google":
+			if provider.Version != "" || provider.LockFile != "" {
+				t.Errorf("google = %+v, want no lock information", provider)
+			}
+		}
+	}
+	if lockedAWS != 2 {
+		t.Errorf("locked aws providers = %v, want 2", lockedAWS)
+	}
+
+	expectedIssues := []LockIssue{
+		{LockFile: lockPath, Provider: "registry.terraform.io/hashicorp/google", Issue: LockIssueMissingFromLock},
+		{LockFile: lockPath, Provider: "registry.terraform.io/hashicorp/random", Issue: LockIssueNotRequired},
+	}
+	if !reflect.DeepEqual(result.LockIssues, expectedIssues) {
+		t.Errorf("LockIssues = %+v, want %+v", result.LockIssues, expectedIssues)
+	}
+}
+
+func TestGenerateWithInvalidLockFile(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_lockfile_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(`module "x" { source = "./x" }`), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, LockFileName), []byte(`provider {`), 0644); err != nil {
+		t.Fatalf("failed to write lock file: %v", err)
+	}
+
+	t.Run("recorded as a diagnostic", func(t *testing.T) {
+		result, err := Generate(tmpDir, false)
+		if err != nil {
+			t.Fatalf("Generate() = %v, want nil", err)
+		}
+		if len(result.Modules) != 1 {
+			t.Errorf("len(result.Modules) = %v, want 1", len(result.Modules))
+		}
+		lockPath := filepath.Join(result.Modules[0].Root, LockFileName)
+		if len(result.Diagnostics) != 1 || result.Diagnostics[0].Severity != DiagnosticError || result.Diagnostics[0].Filename != lockPath {
+			t.Errorf("Diagnostics = %+v, want one error for %s", result.Diagnostics, lockPath)
+		}
+	})
+
+	t.Run("strict", func(t *testing.T) {
+		if _, err := GenerateWithOptions(tmpDir, Options{Strict: true}); err == nil {
+			t.Error("GenerateWithOptions() = nil, want error for an invalid lock file")
+		}
+	})
+}
@@ -0,0 +1,134 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// ModuleManifestPath is where terraform init records installed modules, relative to
+// the directory it was run in
+var ModuleManifestPath = filepath.Join(".terraform", "modules", "modules.json")
+
+// ModuleManifestEntry is a single installed module recorded in modules.json. Key is
+// the dotted path of module call names from the root module, which has an empty key.
+type ModuleManifestEntry struct {
+	Key     string `json:"Key"`
+	Source  string `json:"Source"`
+	Version string `json:"Version,omitempty"`
+	Dir     string `json:"Dir"`
+}
+
+// moduleManifest is the top-level structure of modules.json
+type moduleManifest struct {
+	Modules []ModuleManifestEntry `json:"Modules"`
+}
+
+// ReadModuleManifest reads the module manifest written by terraform init
+func ReadModuleManifest(path string) ([]ModuleManifestEntry, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read module manifest %s: %w", path, err)
+	}
+
+	var manifest moduleManifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse module manifest %s: %w", path, err)
+	}
+
+	return manifest.Modules, nil
+}
+
+// installedModules indexes one module manifest by call path and by installed directory
+type installedModules struct {
+	byKey    map[string]ModuleManifestEntry
+	keyByDir map[string]string
+}
+
+// moduleResolver looks up the installed version of module calls in the manifests
+// found at or above each scanned directory
+type moduleResolver struct {
+	root      string
+	manifests map[string]*installedModules
+}
+
+// newModuleResolver creates a resolver for a scan rooted at root
+func newModuleResolver(root string) *moduleResolver {
+	return &moduleResolver{root: root, manifests: make(map[string]*installedModules)}
+}
+
+// resolve returns the manifest entry for the module call named callName in moduleDir.
+// The call's key is the key of moduleDir itself in the manifest followed by callName.
+func (r *moduleResolver) resolve(moduleDir, callName string) (ModuleManifestEntry, bool, error) {
+	var loadErr error
+	manifestDir := nearestAncestor(r.root, moduleDir, func(dir string) bool {
+		installed, err := r.load(dir)
+		if err != nil {
+			loadErr = err
+			return true
+		}
+		return installed != nil
+	})
+	if loadErr != nil {
+		return ModuleManifestEntry{}, false, loadErr
+	}
+	if manifestDir == "" {
+		return ModuleManifestEntry{}, false, nil
+	}
+
+	installed := r.manifests[manifestDir]
+	parentKey, ok := installed.keyByDir[moduleDir]
+	if !ok {
+		return ModuleManifestEntry{}, false, nil
+	}
+
+	key := callName
+	if parentKey != "" {
+		key = parentKey + "." + callName
+	}
+	entry, ok := installed.byKey[key]
+	return entry, ok, nil
+}
+
+// load reads and indexes the manifest under dir, caching the result. It returns nil
+// when dir has no manifest. A manifest that cannot be read is reported once and then
+// treated as missing.
+func (r *moduleResolver) load(dir string) (*installedModules, error) {
+	if installed, ok := r.manifests[dir]; ok {
+		return installed, nil
+	}
+
+	path := filepath.Join(dir, ModuleManifestPath)
+	if _, err := os.Stat(path); err != nil {
+		r.manifests[dir] = nil
+		return nil, nil
+	}
+
+	entries, err := ReadModuleManifest(path)
+	if err != nil {
+		r.manifests[dir] = nil
+		return nil, err
+	}
+
+	// Sort by key so that a directory used by several calls maps to the first one
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+
+	installed := &installedModules{
+		byKey:    make(map[string]ModuleManifestEntry, len(entries)),
+		keyByDir: make(map[string]string, len(entries)),
+	}
+	for _, entry := range entries {
+		if !filepath.IsAbs(entry.Dir) {
+			entry.Dir = filepath.Join(dir, entry.Dir)
+		}
+		installed.byKey[entry.Key] = entry
+		if _, exists := installed.keyByDir[entry.Dir]; !exists {
+			installed.keyByDir[entry.Dir] = entry.Key
+		}
+	}
+
+	r.manifests[dir] = installed
+	return installed, nil
+}
@@ -0,0 +1,213 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testModuleManifest = `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.1.2","Dir":".terraform/modules/vpc"},
+  {"Key":"app","Source":"git::https://example.com/app.git?ref=v1.0.0","Dir":".terraform/modules/app"},
+  {"Key":"net","Source":"./modules/net","Dir":"modules/net"},
+  {"Key":"net.sg","Source":"registry.terraform.io/terraform-aws-modules/security-group/aws","Version":"4.17.2","Dir":".terraform/modules/net.sg"}
+]}`
+
+func TestReadModuleManifest(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_manifest_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	t.Run("valid manifest", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "modules.json")
+		if err := os.WriteFile(path, []byte(testModuleManifest), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		entries, err := ReadModuleManifest(path)
+		if err != nil {
+			t.Fatalf("ReadModuleManifest() = %v, want nil", err)
+		}
+		if len(entries) != 5 {
+			t.Fatalf("len(entries) = %v, want 5", len(entries))
+		}
+		if entries[1].Key != "vpc" || entries[1].Version != "5.1.2" || entries[1].Dir != ".terraform/modules/vpc" {
+			t.Errorf("entries[1] = %+v, want vpc 5.1.2 in .terraform/modules/vpc", entries[1])
+		}
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		path := filepath.Join(tmpDir, "invalid.json")
+		if err := os.WriteFile(path, []byte(`{"Modules":`), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		_, err := ReadModuleManifest(path)
+		if err == nil {
+			t.Fatal("ReadModuleManifest() = nil, want error for invalid JSON")
+		}
+		if !strings.Contains(err.Error(), "failed to parse module manifest") {
+			t.Errorf("error message = %v, want 'failed to parse module manifest'", err.Error())
+		}
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := ReadModuleManifest(filepath.Join(tmpDir, "missing.json"))
+		if err == nil {
+			t.Fatal("ReadModuleManifest() = nil, want error for missing file")
+		}
+		if !strings.Contains(err.Error(), "failed to read module manifest") {
+			t.Errorf("error message = %v, want 'failed to read module manifest'", err.Error())
+		}
+	})
+}
+
+func TestGenerateResolveInstalled(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_manifest_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	root := `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = ">= 3.0"
+}
+
+module "app" {
+  source = "git::https://example.com/app.git?ref=v1.0.0"
+}
+
+module "net" {
+  source = "./modules/net"
+}
+
+module "not_installed" {
+  source  = "hashicorp/consul/aws"
+  version = "~> 0.1"
+}
+`
+	child := `
+module "sg" {
+  source  = "terraform-aws-modules/security-group/aws"
+  version = "~> 4.0"
+}
+`
+	childDir := filepath.Join(tmpDir, "modules", "net")
+	manifestDir := filepath.Join(tmpDir, ".terraform", "modules")
+	for _, dir := range []string{childDir, manifestDir} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("failed to create %s: %v", dir, err)
+		}
+	}
+	files := map[string]string{
+		filepath.Join(tmpDir, "main.tf"):           root,
+		filepath.Join(childDir, "main.tf"):         child,
+		filepath.Join(manifestDir, "modules.json"): testModuleManifest,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+
+	absDir, _ := filepath.Abs(tmpDir)
+
+	t.Run("resolve installed versions", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, ResolveInstalled: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		modules := make(map[string]ModuleInfo)
+		for _, module := range result.Modules {
+			modules[module.Name] = module
+		}
+
+		vpc := modules["vpc"]
+		if vpc.Version != "5.1.2" {
+			t.Errorf("vpc.Version = %q, want '5.1.2'", vpc.Version)
+		}
+		if vpc.VersionConstraint != ">= 3.0" {
+			t.Errorf("vpc.VersionConstraint = %q, want '>= 3.0'", vpc.VersionConstraint)
+		}
+		if vpc.InstalledDir != filepath.Join(absDir, ".terraform", "modules", "vpc") {
+			t.Errorf("vpc.InstalledDir = %q", vpc.InstalledDir)
+		}
+		if vpc.PURL != "pkg:terraform/terraform-aws-modules/vpc@5.1.2?provider=aws" {
+			t.Errorf("vpc.PURL = %q, want the installed version", vpc.PURL)
+		}
+
+		// Git modules have no version in the manifest but still record their directory
+		app := modules["app"]
+		if app.Version != "" || app.InstalledDir != filepath.Join(absDir, ".terraform", "modules", "app") {
+			t.Errorf("app = %+v, want installed directory and no version", app)
+		}
+
+		// Nested calls are keyed by the call path through the local module
+		sg := modules["sg"]
+		if sg.Version != "4.17.2" || sg.VersionConstraint != "~> 4.0" {
+			t.Errorf("sg = %+v, want version 4.17.2 with constraint '~> 4.0'", sg)
+		}
+
+		// Local modules and calls missing from the manifest are left untouched
+		if net := modules["net"]; net.InstalledDir != "" || net.VersionConstraint != "" {
+			t.Errorf("net = %+v, want no resolution for a local module", net)
+		}
+		if missing := modules["not_installed"]; missing.Version != "~> 0.1" || missing.VersionConstraint != "" {
+			t.Errorf("not_installed = %+v, want the original constraint", missing)
+		}
+	})
+
+	t.Run("resolution is opt-in", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Recursive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		for _, module := range result.Modules {
+			if module.VersionConstraint != "" || module.InstalledDir != "" {
+				t.Errorf("%s = %+v, want no resolution without ResolveInstalled", module.Name, module)
+			}
+		}
+	})
+
+	t.Run("invalid manifest", func(t *testing.T) {
+		invalidDir, err := os.MkdirTemp("", "test_manifest_*")
+		if err != nil {
+			t.Fatalf("failed to create temp directory: %v", err)
+		}
+		defer os.RemoveAll(invalidDir)
+
+		if err := os.MkdirAll(filepath.Join(invalidDir, ".terraform", "modules"), 0755); err != nil {
+			t.Fatalf("failed to create manifest directory: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(invalidDir, "main.tf"), []byte(root), 0644); err != nil {
+			t.Fatalf("failed to write main.tf: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(invalidDir, ModuleManifestPath), []byte("not json"), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+
+		result, err := GenerateWithOptions(invalidDir, Options{ResolveInstalled: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+		if len(result.Modules) != 4 {
+			t.Errorf("len(result.Modules) = %v, want 4 unresolved modules", len(result.Modules))
+		}
+		// The manifest is only reported once, not once per module call
+		if len(result.Diagnostics) != 1 || !strings.Contains(result.Diagnostics[0].Message, "failed to parse module manifest") {
+			t.Errorf("Diagnostics = %+v, want one manifest error", result.Diagnostics)
+		}
+
+		if _, err := GenerateWithOptions(invalidDir, Options{ResolveInstalled: true, Strict: true}); err == nil {
+			t.Error("GenerateWithOptions() = nil, want error for an invalid manifest in strict mode")
+		}
+	})
+}
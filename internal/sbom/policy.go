@@ -0,0 +1,19 @@
+package sbom
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// String formats the violation as "file:line: message [rule]"
+func (v PolicyViolation) String() string {
+	position := v.Filename
+	if v.Line > 0 {
+		position += ":" + strconv.Itoa(v.Line)
+	}
+	message := fmt.Sprintf("module %q: %s [%s]", v.Module, v.Message, v.Rule)
+	if position == "" {
+		return message
+	}
+	return position + ": " + message
+}
@@ -0,0 +1,23 @@
+package sbom
+
+import "testing"
+
+func TestPolicyViolationString(t *testing.T) {
+	tests := []struct {
+		name      string
+		violation PolicyViolation
+		want      string
+	}{
+		{"with position", PolicyViolation{Rule: "denied-git-ref", Module: "app", Filename: "main.tf", Line: 5, Message: `git ref "main" is not allowed`}, `main.tf:5: module "app": git ref "main" is not allowed [denied-git-ref]`},
+		{"without line", PolicyViolation{Rule: "allowed-host", Module: "app", Filename: "main.tf", Message: "host is not allowed"}, `main.tf: module "app": host is not allowed [allowed-host]`},
+		{"without position", PolicyViolation{Rule: "allowed-host", Module: "app", Message: "host is not allowed"}, `module "app": host is not allowed [allowed-host]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.violation.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
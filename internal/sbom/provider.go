@@ -0,0 +1,181 @@
+package sbom
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// DefaultProviderNamespace is the namespace Terraform assumes for providers without a source
+const DefaultProviderNamespace = "hashicorp"
+
+// configFileSchema selects the blocks that can declare a provider dependency or module call
+var configFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "provider", LabelNames: []string{"name"}},
+		{Type: "module", LabelNames: []string{"name"}},
+	},
+}
+
+// terraformBlockSchema selects the required_providers blocks nested in a terraform block,
+// and the backend and cloud blocks that mark a root configuration
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "required_providers"},
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+	},
+}
+
+// ProviderSourceAddress returns the fully-qualified source address Terraform installs a
+// provider from. An empty source defaults to the hashicorp namespace on the public registry.
+func ProviderSourceAddress(localName, source string) string {
+	parts := strings.Split(source, "/")
+	switch {
+	case source == "":
+		return DefaultRegistryHost + "/" + DefaultProviderNamespace + "/" + localName
+	case len(parts) == 1:
+		return DefaultRegistryHost + "/" + DefaultProviderNamespace + "/" + source
+	case len(parts) == 2:
+		return DefaultRegistryHost + "/" + source
+	}
+	return source
+}
+
+// Constraint joins the provider's version constraints into a single constraint string
+func (p ProviderInfo) Constraint() string {
+	return strings.Join(p.VersionConstraints, ", ")
+}
+
+// EffectiveVersion returns the locked version when known, otherwise the version constraint
+func (p ProviderInfo) EffectiveVersion() string {
+	if p.Version != "" {
+		return p.Version
+	}
+	return p.Constraint()
+}
+
+// SourceParts splits the provider's source address into its hostname, namespace and type
+func (p ProviderInfo) SourceParts() (host, namespace, providerType string) {
+	parts := strings.Split(ProviderSourceAddress(p.Name, p.Source), "/")
+	if len(parts) != 3 {
+		return "", "", ""
+	}
+	return parts[0], parts[1], parts[2]
+}
+
+// collectProviders converts the provider requirements of a loaded module into
+// ProviderInfo entries sorted by local name, positioned by the declarations in positions
+func collectProviders(moduleDir string, module *tfconfig.Module, positions map[string]hcl.Range) []ProviderInfo {
+	names := make([]string, 0, len(module.RequiredProviders))
+	for name := range module.RequiredProviders {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	providers := make([]ProviderInfo, 0, len(names))
+	for _, name := range names {
+		requirement := module.RequiredProviders[name]
+		provider := ProviderInfo{
+			Name:               name,
+			Source:             ProviderSourceAddress(name, requirement.Source),
+			VersionConstraints: requirement.VersionConstraints,
+			Directory:          moduleDir,
+		}
+		if rng, ok := positions[name]; ok {
+			provider.Location = fmt.Sprintf("Provider requirement at %s:%d", rng.Filename, rng.Start.Line)
+			provider.Filename = rng.Filename
+			provider.Line, provider.Column = rng.Start.Line, rng.Start.Column
+		}
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// declarations records where the providers and module calls of a directory are declared
+type declarations struct {
+	providers map[string]hcl.Range
+	modules   map[string]hcl.Range
+	// root reports whether the directory configures a backend, HCP Terraform or a provider
+	root bool
+}
+
+// parseDeclarations finds the position declaring each provider and module call in a
+// directory. An entry in required_providers takes precedence over a provider
+// configuration block. Parse errors are ignored here since tfconfig already reports them.
+func parseDeclarations(moduleDir string) declarations {
+	required := make(map[string]hcl.Range)
+	configured := make(map[string]hcl.Range)
+	modules := make(map[string]hcl.Range)
+	backend := false
+
+	record := func(positions map[string]hcl.Range, name string, rng hcl.Range) {
+		if _, exists := positions[name]; !exists {
+			positions[name] = rng
+		}
+	}
+
+	parser := hclparse.NewParser()
+	for _, filename := range configFiles(moduleDir) {
+		var file *hcl.File
+		if strings.HasSuffix(filename, ".json") {
+			file, _ = parser.ParseJSONFile(filename)
+		} else {
+			file, _ = parser.ParseHCLFile(filename)
+		}
+		if file == nil {
+			continue
+		}
+
+		content, _, _ := file.Body.PartialContent(configFileSchema)
+		for _, block := range content.Blocks {
+			switch block.Type {
+			case "terraform":
+				inner, _, _ := block.Body.PartialContent(terraformBlockSchema)
+				for _, nested := range inner.Blocks {
+					if nested.Type != "required_providers" {
+						backend = true
+						continue
+					}
+					attrs, _ := nested.Body.JustAttributes()
+					for name, attr := range attrs {
+						record(required, name, attr.NameRange)
+					}
+				}
+			case "provider":
+				record(configured, block.Labels[0], block.DefRange)
+			case "module":
+				record(modules, block.Labels[0], block.DefRange)
+			}
+		}
+	}
+
+	root := backend || len(configured) > 0
+	for name, rng := range configured {
+		if _, exists := required[name]; !exists {
+			required[name] = rng
+		}
+	}
+	return declarations{providers: required, modules: modules, root: root}
+}
+
+// configFiles lists the Terraform configuration files in a directory in name order
+func configFiles(moduleDir string) []string {
+	var files []string
+	for _, pattern := range []string{"*.tf", "*.tf.json"} {
+		matches, err := filepath.Glob(filepath.Join(moduleDir, pattern))
+		if err != nil {
+			continue
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files
+}
@@ -0,0 +1,130 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestProviderSourceAddress(t *testing.T) {
+	tests := []struct {
+		name      string
+		localName string
+		source    string
+		expected  string
+	}{
+		{"implicit source", "aws", "", "registry.terraform.io/hashicorp/aws"},
+		{"type only", "aws", "aws", "registry.terraform.io/hashicorp/aws"},
+		{"namespace and type", "azurerm", "hashicorp/azurerm", "registry.terraform.io/hashicorp/azurerm"},
+		{"fully qualified", "example", "terraform.example.com/examplecorp/example", "terraform.example.com/examplecorp/example"},
+		{"local name differs from type", "awsalt", "hashicorp/aws", "registry.terraform.io/hashicorp/aws"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := ProviderSourceAddress(test.localName, test.source)
+			if result != test.expected {
+				t.Errorf("ProviderSourceAddress(%q, %q) = %q, want %q", test.localName, test.source, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestProviderInfoSourceParts(t *testing.T) {
+	provider := ProviderInfo{Name: "example", Source: "terraform.example.com/examplecorp/example"}
+	host, namespace, providerType := provider.SourceParts()
+	if host != "terraform.example.com" || namespace != "examplecorp" || providerType != "example" {
+		t.Errorf("SourceParts() = %q, %q, %q, want terraform.example.com, examplecorp, example", host, namespace, providerType)
+	}
+
+	provider = ProviderInfo{Name: "bad", Source: "a/b/c/d"}
+	if host, _, _ := provider.SourceParts(); host != "" {
+		t.Errorf("SourceParts() host = %q, want empty for malformed source", host)
+	}
+}
+
+func TestGenerateProviders(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_providers_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	versions := `
+terraform {
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 5.0"
+    }
+    example = {
+      source = "terraform.example.com/examplecorp/example"
+    }
+  }
+}
+`
+	main := `
+provider "google" {
+  version = ">= 4.0"
+}
+
+provider "aws" {
+  region = "us-east-1"
+}
+`
+	if err := os.WriteFile(filepath.Join(tmpDir, "versions.tf"), []byte(versions), 0644); err != nil {
+		t.Fatalf("failed to write versions.tf: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "main.tf"), []byte(main), 0644); err != nil {
+		t.Fatalf("failed to write main.tf: %v", err)
+	}
+
+	result, err := Generate(tmpDir, false)
+	if err != nil {
+		t.Fatalf("Generate() = %v, want nil", err)
+	}
+
+	absDir, _ := filepath.Abs(tmpDir)
+	versionsFile := filepath.Join(absDir, "versions.tf")
+	mainFile := filepath.Join(absDir, "main.tf")
+
+	expected := []ProviderInfo{
+		{
+			Name:               "aws",
+			Source:             "registry.terraform.io/hashicorp/aws",
+			VersionConstraints: []string{"~> 5.0"},
+			Location:           "Provider requirement at " + versionsFile + ":4",
+			Filename:           versionsFile,
+			Line:               4,
+			Column:             5,
+			FilePath:           "versions.tf",
+			Directory:          absDir,
+		},
+		{
+			Name:      "example",
+			Source:    "terraform.example.com/examplecorp/example",
+			Location:  "Provider requirement at " + versionsFile + ":8",
+			Filename:  versionsFile,
+			Line:      8,
+			Column:    5,
+			FilePath:  "versions.tf",
+			Directory: absDir,
+		},
+		{
+			Name:               "google",
+			Source:             "registry.terraform.io/hashicorp/google",
+			VersionConstraints: []string{">= 4.0"},
+			Location:           "Provider requirement at " + mainFile + ":2",
+			Filename:           mainFile,
+			Line:               2,
+			Column:             1,
+			FilePath:           "main.tf",
+			Directory:          absDir,
+		},
+	}
+
+	if !reflect.DeepEqual(result.Providers, expected) {
+		t.Errorf("Providers = %+v, want %+v", result.Providers, expected)
+	}
+}
@@ -0,0 +1,145 @@
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// exactVersionPattern matches a single pinned version such as "5.1.0", "v1.2.3" or "= 1.0.0"
+var exactVersionPattern = regexp.MustCompile(`^=?\s*v?(\d+(?:\.\d+)*(?:-[0-9A-Za-z.-]+)?(?:\+[0-9A-Za-z.-]+)?)$`)
+
+// PackageURL computes a package URL (purl) for a module source and version constraint.
+// Registry modules map to pkg:terraform, GitHub and Bitbucket shorthands to
+// pkg:github and pkg:bitbucket, and other remote sources to pkg:generic with a
+// vcs_url or download_url qualifier. Local paths have no purl and return "".
+func PackageURL(source, version string) string {
+	parsed := ParseSource(source)
+
+	switch parsed.Kind {
+	case SourceRegistry:
+		// Registry addresses only carry a version when pinned to an exact release
+		exact, _ := ExactVersion(version)
+		p := purl{
+			Type:       "terraform",
+			Namespace:  parsed.Namespace,
+			Name:       parsed.Name,
+			Version:    exact,
+			Subpath:    parsed.Subdirectory,
+			Qualifiers: map[string]string{"provider": parsed.Provider},
+		}
+		if parsed.Host != DefaultRegistryHost {
+			p.Qualifiers["repository_url"] = parsed.Host
+		}
+		return p.String()
+
+	case SourceGitHub, SourceBitbucket:
+		return purl{
+			Type:      string(parsed.Kind),
+			Namespace: parsed.Namespace,
+			Name:      parsed.Name,
+			Version:   parsed.Ref,
+			Subpath:   parsed.Subdirectory,
+		}.String()
+
+	case SourceGit, SourceMercurial:
+		vcsURL := string(parsed.Kind) + "+" + parsed.URL
+		if parsed.Ref != "" {
+			vcsURL += "@" + parsed.Ref
+		}
+		return purl{
+			Type:       "generic",
+			Name:       parsed.Name,
+			Version:    parsed.Ref,
+			Subpath:    parsed.Subdirectory,
+			Qualifiers: map[string]string{"vcs_url": vcsURL},
+		}.String()
+
+	case SourceHTTP, SourceS3, SourceGCS:
+		downloadURL := parsed.URL
+		if query := parsed.QueryString(); query != "" {
+			downloadURL += "?" + query
+		}
+		return purl{
+			Type:       "generic",
+			Name:       parsed.Name,
+			Subpath:    parsed.Subdirectory,
+			Qualifiers: map[string]string{"download_url": downloadURL},
+		}.String()
+	}
+
+	return ""
+}
+
+// ExactVersion returns the version a constraint pins to, without any "=" operator or
+// "v" prefix, and reports whether the constraint selects exactly one version
+func ExactVersion(constraint string) (string, bool) {
+	match := exactVersionPattern.FindStringSubmatch(strings.TrimSpace(constraint))
+	if match == nil {
+		return "", false
+	}
+	return match[1], true
+}
+
+// purl holds the components of a package URL
+type purl struct {
+	Type       string
+	Namespace  string
+	Name       string
+	Version    string
+	Qualifiers map[string]string
+	Subpath    string
+}
+
+// String renders the package URL in its canonical form
+func (p purl) String() string {
+	var b strings.Builder
+	b.WriteString("pkg:" + p.Type + "/")
+	if p.Namespace != "" {
+		b.WriteString(purlEscape(p.Namespace, "/") + "/")
+	}
+	b.WriteString(purlEscape(p.Name, ""))
+	if p.Version != "" {
+		b.WriteString("@" + purlEscape(p.Version, ""))
+	}
+
+	if len(p.Qualifiers) > 0 {
+		keys := make([]string, 0, len(p.Qualifiers))
+		for key := range p.Qualifiers {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for i, key := range keys {
+			separator := "&"
+			if i == 0 {
+				separator = "?"
+			}
+			b.WriteString(fmt.Sprintf("%s%s=%s", separator, key, purlEscape(p.Qualifiers[key], ":/")))
+		}
+	}
+
+	if p.Subpath != "" {
+		b.WriteString("#" + purlEscape(p.Subpath, "/"))
+	}
+
+	return b.String()
+}
+
+// purlEscape percent-encodes everything except unreserved characters and the
+// additional characters in allowed
+func purlEscape(value, allowed string) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9',
+			c == '-', c == '.', c == '_', c == '~', strings.IndexByte(allowed, c) != -1:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package sbom
+
+import "testing"
+
+func TestPackageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		version  string
+		expected string
+	}{
+		{"registry exact version", "terraform-aws-modules/vpc/aws", "5.1.0", "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws"},
+		{"registry v-prefixed version", "terraform-aws-modules/vpc/aws", "v5.1.0", "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws"},
+		{"registry equals version", "terraform-aws-modules/vpc/aws", "= 5.1.0", "pkg:terraform/terraform-aws-modules/vpc@5.1.0?provider=aws"},
+		{"registry constraint", "terraform-aws-modules/vpc/aws", "~> 5.0", "pkg:terraform/terraform-aws-modules/vpc?provider=aws"},
+		{"registry no version", "terraform-aws-modules/vpc/aws", "", "pkg:terraform/terraform-aws-modules/vpc?provider=aws"},
+		{"registry default host", "registry.terraform.io/hashicorp/consul/aws", "0.1.0", "pkg:terraform/hashicorp/consul@0.1.0?provider=aws"},
+		{"private registry", "app.terraform.io/example-corp/k8s-cluster/azurerm", "1.0.0", "pkg:terraform/example-corp/k8s-cluster@1.0.0?provider=azurerm&repository_url=app.terraform.io"},
+		{"registry submodule", "hashicorp/consul/aws//modules/consul-cluster", "0.1.0", "pkg:terraform/hashicorp/consul@0.1.0?provider=aws#modules/consul-cluster"},
+		{"github shorthand", "github.com/hashicorp/example", "", "pkg:github/hashicorp/example"},
+		{"github shorthand with ref", "github.com/hashicorp/example?ref=v1.2.0", "", "pkg:github/hashicorp/example@v1.2.0"},
+		{"github shorthand with subdir", "github.com/hashicorp/example//modules/vpc?ref=v1.2.0", "", "pkg:github/hashicorp/example@v1.2.0#modules/vpc"},
+		{"github shorthand with .git", "github.com/hashicorp/example.git", "", "pkg:github/hashicorp/example"},
+		{"bitbucket shorthand", "bitbucket.org/hashicorp/terraform-consul-aws", "", "pkg:bitbucket/hashicorp/terraform-consul-aws"},
+		{"git https", "git::https://example.com/vpc.git", "", "pkg:generic/vpc?vcs_url=git%2Bhttps://example.com/vpc.git"},
+		{"git https with ref", "git::https://example.com/vpc.git?ref=v1.2.0", "", "pkg:generic/vpc@v1.2.0?vcs_url=git%2Bhttps://example.com/vpc.git%40v1.2.0"},
+		{"git ssh with subdir", "git::ssh://git@example.com/network.git//modules/core?ref=51d462976d84fdea54b47d80dcabbf680badcdb8", "", "pkg:generic/network@51d462976d84fdea54b47d80dcabbf680badcdb8?vcs_url=git%2Bssh://git%40example.com/network.git%4051d462976d84fdea54b47d80dcabbf680badcdb8#modules/core"},
+		{"git scp-like", "git@github.com:hashicorp/example.git", "", "pkg:generic/example?vcs_url=git%2Bssh://git%40github.com/hashicorp/example.git"},
+		{"mercurial", "hg::http://example.com/vpc.hg?ref=default", "", "pkg:generic/vpc.hg@default?vcs_url=hg%2Bhttp://example.com/vpc.hg%40default"},
+		{"http archive", "https://example.com/vpc-module.zip", "", "pkg:generic/vpc-module?download_url=https://example.com/vpc-module.zip"},
+		{"s3 archive", "s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip", "", "pkg:generic/vpc?download_url=https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip"},
+		{"gcs archive", "gcs::https://www.googleapis.com/storage/v1/modules/foomodule.tar.gz", "", "pkg:generic/foomodule?download_url=https://www.googleapis.com/storage/v1/modules/foomodule.tar.gz"},
+		{"local path", "./modules/local", "", ""},
+		{"parent local path", "../shared", "", ""},
+		{"empty source", "", "", ""},
+		{"unrecognized source", "not a source", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := PackageURL(test.source, test.version)
+			if result != test.expected {
+				t.Errorf("PackageURL(%q, %q) = %q, want %q", test.source, test.version, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestExactVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		expected   string
+		exact      bool
+	}{
+		{"5.1.0", "5.1.0", true},
+		{"v5.1.0", "5.1.0", true},
+		{"= 5.1.0", "5.1.0", true},
+		{" 1.0.0-beta.1 ", "1.0.0-beta.1", true},
+		{"~> 5.0", "", false},
+		{">= 1.0, < 2.0", "", false},
+		{"", "", false},
+	}
+
+	for _, test := range tests {
+		result, exact := ExactVersion(test.constraint)
+		if result != test.expected || exact != test.exact {
+			t.Errorf("ExactVersion(%q) = %q, %v, want %q, %v", test.constraint, result, exact, test.expected, test.exact)
+		}
+	}
+}
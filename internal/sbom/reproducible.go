@@ -0,0 +1,107 @@
+package sbom
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SourceDateEpochEnv is the environment variable that fixes the SBOM timestamp, as
+// defined by https://reproducible-builds.org/specs/source-date-epoch/
+const SourceDateEpochEnv = "SOURCE_DATE_EPOCH"
+
+// generatedTimestamp returns the SBOM creation time. SOURCE_DATE_EPOCH always takes
+// precedence; otherwise reproducible SBOMs use the Unix epoch and others the current time.
+func generatedTimestamp(reproducible bool) (string, error) {
+	if epoch := os.Getenv(SourceDateEpochEnv); epoch != "" {
+		seconds, err := strconv.ParseInt(epoch, 10, 64)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s %q: %w", SourceDateEpochEnv, epoch, err)
+		}
+		return time.Unix(seconds, 0).UTC().Format(time.RFC3339), nil
+	}
+	if reproducible {
+		return time.Unix(0, 0).UTC().Format(time.RFC3339), nil
+	}
+	return time.Now().Format(time.RFC3339), nil
+}
+
+// sortModules orders modules by file, line, column and name so that the order does not
+// depend on how directories were walked
+func sortModules(modules []ModuleInfo) {
+	sort.SliceStable(modules, func(i, j int) bool {
+		a, b := modules[i], modules[j]
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if lineA, lineB := a.StartLine(), b.StartLine(); lineA != lineB {
+			return lineA < lineB
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Path < b.Path
+	})
+}
+
+// relativizePaths rewrites every path recorded in the SBOM relative to base, using
+// forward slashes, so that the same configuration yields the same SBOM wherever it is
+// checked out
+func relativizePaths(s *SBOM, base string) {
+	path := func(p string) string {
+		if p == "" || !filepath.IsAbs(p) {
+			return p
+		}
+		if rel, err := filepath.Rel(base, p); err == nil {
+			return filepath.ToSlash(rel)
+		}
+		return p
+	}
+	prefix := base + string(filepath.Separator)
+	text := func(t string) string {
+		return strings.ReplaceAll(t, prefix, "")
+	}
+
+	modules := func(modules []ModuleInfo) {
+		for i := range modules {
+			m := &modules[i]
+			m.Location, m.Filename = text(m.Location), path(m.Filename)
+			m.InstalledDir, m.Root = path(m.InstalledDir), path(m.Root)
+		}
+	}
+
+	modules(s.Modules)
+	for i := range s.Configurations {
+		modules(s.Configurations[i].Modules)
+	}
+	for i := range s.Providers {
+		p := &s.Providers[i]
+		p.Location, p.Filename = text(p.Location), path(p.Filename)
+		p.Directory, p.LockFile = path(p.Directory), path(p.LockFile)
+	}
+	for i := range s.LockIssues {
+		s.LockIssues[i].LockFile = path(s.LockIssues[i].LockFile)
+	}
+	for i := range s.Resources {
+		r := &s.Resources[i]
+		r.Location, r.Filename, r.Directory = text(r.Location), path(r.Filename), path(r.Directory)
+	}
+	for i := range s.TerraformCore {
+		s.TerraformCore[i].Directory = path(s.TerraformCore[i].Directory)
+	}
+	for i := range s.CoreConflicts {
+		c := &s.CoreConflicts[i]
+		c.Directory, c.ConflictingDirectory = path(c.Directory), path(c.ConflictingDirectory)
+	}
+	for i := range s.Diagnostics {
+		d := &s.Diagnostics[i]
+		d.Directory, d.Filename, d.Message = path(d.Directory), path(d.Filename), text(d.Message)
+	}
+}
@@ -0,0 +1,124 @@
+package sbom
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+)
+
+func TestGeneratedTimestamp(t *testing.T) {
+	t.Run("SOURCE_DATE_EPOCH", func(t *testing.T) {
+		t.Setenv(SourceDateEpochEnv, "1700000000")
+		for _, reproducible := range []bool{false, true} {
+			result, err := generatedTimestamp(reproducible)
+			if err != nil {
+				t.Fatalf("generatedTimestamp(%v) = %v, want nil", reproducible, err)
+			}
+			if result != "2023-11-14T22:13:20Z" {
+				t.Errorf("generatedTimestamp(%v) = %q, want '2023-11-14T22:13:20Z'", reproducible, result)
+			}
+		}
+	})
+
+	t.Run("reproducible without SOURCE_DATE_EPOCH", func(t *testing.T) {
+		t.Setenv(SourceDateEpochEnv, "")
+		result, err := generatedTimestamp(true)
+		if err != nil {
+			t.Fatalf("generatedTimestamp(true) = %v, want nil", err)
+		}
+		if result != "1970-01-01T00:00:00Z" {
+			t.Errorf("generatedTimestamp(true) = %q, want the Unix epoch", result)
+		}
+	})
+
+	t.Run("invalid SOURCE_DATE_EPOCH", func(t *testing.T) {
+		t.Setenv(SourceDateEpochEnv, "yesterday")
+		if _, err := generatedTimestamp(false); err == nil {
+			t.Error("generatedTimestamp() = nil, want error for an invalid SOURCE_DATE_EPOCH")
+		}
+	})
+}
+
+func TestSortModules(t *testing.T) {
+	modules := []ModuleInfo{
+		{Name: "b", Location: "Module call at /project/main.tf:10", Filename: "/project/main.tf"},
+		{Name: "z", Location: "Module call at /project/app/main.tf:1", Filename: "/project/app/main.tf"},
+		{Name: "a", Location: "Module call at /project/main.tf:10", Filename: "/project/main.tf"},
+		{Name: "c", Location: "Module call at /project/main.tf:2", Filename: "/project/main.tf"},
+	}
+	sortModules(modules)
+
+	var names []string
+	for _, module := range modules {
+		names = append(names, module.Name)
+	}
+	if expected := []string{"z", "c", "a", "b"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("sorted modules = %v, want %v", names, expected)
+	}
+}
+
+func TestGenerateReproducible(t *testing.T) {
+	t.Setenv(SourceDateEpochEnv, "")
+
+	files := map[string]string{
+		"main.tf": `
+terraform {
+  required_version = ">= 1.5"
+}
+
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
+
+module "app" {
+  source = "./modules/app"
+}
+`,
+		"modules/app/main.tf": `
+module "sg" {
+  source = "git::https://example.com/sg.git?ref=v1.0.0"
+}
+
+resource "aws_s3_bucket" "logs" {}
+`,
+	}
+
+	// The same configuration checked out in two places
+	var outputs [][]byte
+	for range 2 {
+		dir := t.TempDir()
+		writeFiles(t, dir, files)
+
+		result, err := GenerateWithOptions(dir, Options{Recursive: true, Transitive: true, Resources: true, Reproducible: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		if result.Generated != "1970-01-01T00:00:00Z" {
+			t.Errorf("Generated = %q, want the Unix epoch", result.Generated)
+		}
+		vpc := result.Modules[0]
+		if vpc.Name != "vpc" || vpc.Filename != "main.tf" || vpc.Location != "Module call at main.tf:6" || vpc.Root != "." {
+			t.Errorf("Modules[0] = %+v, want vpc called from main.tf:6 relative to the root", vpc)
+		}
+		if result.Resources[0].Directory != "modules/app" {
+			t.Errorf("Resources[0].Directory = %q, want 'modules/app'", result.Resources[0].Directory)
+		}
+
+		jsonOutput, err := json.Marshal(result)
+		if err != nil {
+			t.Fatalf("json.Marshal() = %v, want nil", err)
+		}
+		xmlOutput, err := xml.Marshal(result)
+		if err != nil {
+			t.Fatalf("xml.Marshal() = %v, want nil", err)
+		}
+		outputs = append(outputs, append(jsonOutput, xmlOutput...))
+	}
+
+	if string(outputs[0]) != string(outputs[1]) {
+		t.Errorf("reproducible SBOMs differ:\n%s\n%s", outputs[0], outputs[1])
+	}
+}
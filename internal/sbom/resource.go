@@ -0,0 +1,81 @@
+package sbom
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hashicorp/terraform-config-inspect/tfconfig"
+)
+
+// Resource modes recorded in ResourceInfo.Mode
+const (
+	ResourceModeManaged = "managed"
+	ResourceModeData    = "data"
+)
+
+// collectResources converts the managed resources and data sources of a loaded module
+// into ResourceInfo entries sorted by address. Each resource is mapped to the source
+// address of the provider it uses.
+func collectResources(moduleDir string, module *tfconfig.Module) []ResourceInfo {
+	resources := make([]ResourceInfo, 0, len(module.ManagedResources)+len(module.DataResources))
+	for _, blocks := range []map[string]*tfconfig.Resource{module.ManagedResources, module.DataResources} {
+		for address, resource := range blocks {
+			mode := ResourceModeManaged
+			description := "Resource"
+			if resource.Mode == tfconfig.DataResourceMode {
+				mode = ResourceModeData
+				description = "Data source"
+			}
+
+			provider := resource.Provider.Name
+			if resource.Provider.Alias != "" {
+				provider += "." + resource.Provider.Alias
+			}
+
+			source := ""
+			if requirement, ok := module.RequiredProviders[resource.Provider.Name]; ok {
+				source = requirement.Source
+			}
+
+			resources = append(resources, ResourceInfo{
+				Address:        address,
+				Mode:           mode,
+				Type:           resource.Type,
+				Name:           resource.Name,
+				Provider:       provider,
+				ProviderSource: ProviderSourceAddress(resource.Provider.Name, source),
+				Location:       fmt.Sprintf("%s at %s:%d", description, resource.Pos.Filename, resource.Pos.Line),
+				Filename:       resource.Pos.Filename,
+				Directory:      moduleDir,
+			})
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources
+}
+
+// summarizeResources counts resources and data sources per provider source address,
+// sorted by provider
+func summarizeResources(resources []ResourceInfo) []ResourceSummary {
+	counts := make(map[string]*ResourceSummary)
+	for _, resource := range resources {
+		summary, ok := counts[resource.ProviderSource]
+		if !ok {
+			summary = &ResourceSummary{Provider: resource.ProviderSource}
+			counts[resource.ProviderSource] = summary
+		}
+		if resource.Mode == ResourceModeData {
+			summary.DataResources++
+		} else {
+			summary.ManagedResources++
+		}
+	}
+
+	summaries := make([]ResourceSummary, 0, len(counts))
+	for _, summary := range counts {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Provider < summaries[j].Provider })
+	return summaries
+}
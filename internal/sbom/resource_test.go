@@ -0,0 +1,93 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateResources(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_resources_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"main.tf": `
+terraform {
+  required_providers {
+    corp = {
+      source = "terraform.example.com/examplecorp/corp"
+    }
+  }
+}
+
+resource "aws_iam_role" "this" {}
+
+resource "aws_iam_policy" "this" {
+  provider = aws.west
+}
+
+data "aws_caller_identity" "current" {}
+
+resource "corp_widget" "w" {}
+
+module "child" {
+  source = "./child"
+}
+`,
+		"child/main.tf": `
+resource "random_id" "suffix" {}
+`,
+	})
+
+	absDir, _ := filepath.Abs(tmpDir)
+	mainFile := filepath.Join(absDir, "main.tf")
+
+	t.Run("records resources and counts them per provider", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Resources: true, Transitive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		expected := []ResourceInfo{
+			{Address: "aws_iam_policy.this", Mode: ResourceModeManaged, Type: "aws_iam_policy", Name: "this", Provider: "aws.west", ProviderSource: "registry.terraform.io/hashicorp/aws", Location: "Resource at " + mainFile + ":12", Filename: mainFile, Directory: absDir},
+			{Address: "aws_iam_role.this", Mode: ResourceModeManaged, Type: "aws_iam_role", Name: "this", Provider: "aws", ProviderSource: "registry.terraform.io/hashicorp/aws", Location: "Resource at " + mainFile + ":10", Filename: mainFile, Directory: absDir},
+			{Address: "corp_widget.w", Mode: ResourceModeManaged, Type: "corp_widget", Name: "w", Provider: "corp", ProviderSource: "terraform.example.com/examplecorp/corp", Location: "Resource at " + mainFile + ":18", Filename: mainFile, Directory: absDir},
+			{Address: "data.aws_caller_identity.current", Mode: ResourceModeData, Type: "aws_caller_identity", Name: "current", Provider: "aws", ProviderSource: "registry.terraform.io/hashicorp/aws", Location: "Data source at " + mainFile + ":16", Filename: mainFile, Directory: absDir},
+		}
+		if len(result.Resources) != len(expected)+1 {
+			t.Fatalf("len(result.Resources) = %v, want %v", len(result.Resources), len(expected)+1)
+		}
+		if !reflect.DeepEqual(result.Resources[:len(expected)], expected) {
+			t.Errorf("Resources = %+v, want %+v", result.Resources[:len(expected)], expected)
+		}
+
+		// Resources of transitively loaded modules are recorded against their directory
+		child := result.Resources[len(expected)]
+		if child.Address != "random_id.suffix" || child.Directory != filepath.Join(absDir, "child") {
+			t.Errorf("Resources[%d] = %+v, want random_id.suffix in child", len(expected), child)
+		}
+
+		expectedSummary := []ResourceSummary{
+			{Provider: "registry.terraform.io/hashicorp/aws", ManagedResources: 2, DataResources: 1},
+			{Provider: "registry.terraform.io/hashicorp/random", ManagedResources: 1},
+			{Provider: "terraform.example.com/examplecorp/corp", ManagedResources: 1},
+		}
+		if !reflect.DeepEqual(result.ResourceSummary, expectedSummary) {
+			t.Errorf("ResourceSummary = %+v, want %+v", result.ResourceSummary, expectedSummary)
+		}
+	})
+
+	t.Run("resource inventory is opt-in", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+		if result.Resources != nil || result.ResourceSummary != nil {
+			t.Errorf("Resources = %+v, ResourceSummary = %+v, want none without Resources", result.Resources, result.ResourceSummary)
+		}
+	})
+}
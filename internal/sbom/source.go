@@ -0,0 +1,248 @@
+package sbom
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SourceKind identifies how Terraform retrieves a module
+type SourceKind string
+
+const (
+	SourceLocal     SourceKind = "local"
+	SourceRegistry  SourceKind = "registry"
+	SourceGitHub    SourceKind = "github"
+	SourceBitbucket SourceKind = "bitbucket"
+	SourceGit       SourceKind = "git"
+	SourceMercurial SourceKind = "hg"
+	SourceHTTP      SourceKind = "http"
+	SourceS3        SourceKind = "s3"
+	SourceGCS       SourceKind = "gcs"
+	SourceUnknown   SourceKind = "unknown"
+)
+
+// DefaultRegistryHost is the registry used when a registry source has no hostname
+const DefaultRegistryHost = "registry.terraform.io"
+
+// ModuleSource is a module source address decomposed into its parts
+type ModuleSource struct {
+	Kind          SourceKind    `json:"kind" xml:"kind"`
+	Host          string        `json:"host,omitempty" xml:"host,omitempty"`
+	Namespace     string        `json:"namespace,omitempty" xml:"namespace,omitempty"`
+	Name          string        `json:"name,omitempty" xml:"name,omitempty"`
+	Provider      string        `json:"provider,omitempty" xml:"provider,omitempty"`
+	URL           string        `json:"url,omitempty" xml:"url,omitempty"`
+	Subdirectory  string        `json:"subdirectory,omitempty" xml:"subdirectory,omitempty"`
+	Ref           string        `json:"ref,omitempty" xml:"ref,omitempty"`
+	ArchiveFormat string        `json:"archive_format,omitempty" xml:"archive_format,omitempty"`
+	Query         []SourceParam `json:"query,omitempty" xml:"query>param,omitempty"`
+}
+
+// SourceParam is a single query parameter from a module source address
+type SourceParam struct {
+	Name  string `json:"name" xml:"name,attr"`
+	Value string `json:"value" xml:",chardata"`
+}
+
+// registrySourcePattern matches Terraform registry addresses: [host/]namespace/name/provider
+var registrySourcePattern = regexp.MustCompile(`^(?:([a-zA-Z0-9.-]+\.[a-zA-Z]{2,})/)?([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9][a-zA-Z0-9_-]*)/([a-zA-Z0-9]+)$`)
+
+// archiveExtensions are the archive formats go-getter recognizes by file extension
+var archiveExtensions = []string{"tar.gz", "tar.bz2", "tar.xz", "tgz", "tbz2", "txz", "zip", "tar", "gz", "bz2", "xz"}
+
+// ParseSource decomposes a module source address into a ModuleSource following the
+// address forms documented for Terraform module sources: local paths, registry
+// addresses, GitHub and Bitbucket shorthands, and git, hg, http, s3 and gcs
+// sources with an optional forced getter prefix, "//subdir" and query string.
+func ParseSource(source string) ModuleSource {
+	if IsLocalSource(source) {
+		return ModuleSource{Kind: SourceLocal, Name: path.Base(strings.TrimSuffix(source, "/"))}
+	}
+
+	address, subdir, query := splitSourceAddress(source)
+	parsed := ModuleSource{
+		Kind:         SourceUnknown,
+		Subdirectory: subdir,
+		Ref:          query.Get("ref"),
+		Query:        sourceParams(query),
+	}
+
+	// Forced getters, e.g. git::https://example.com/vpc.git
+	getter := ""
+	if prefix, rest, ok := strings.Cut(address, "::"); ok && !strings.Contains(prefix, "/") {
+		getter, address = prefix, rest
+	}
+
+	registryMatch := registrySourcePattern.FindStringSubmatch(address)
+
+	switch {
+	case address == "":
+		return parsed
+
+	case getter == "" && registryMatch != nil && !isVCSHost(registryMatch[1]):
+		match := registryMatch
+		parsed.Kind = SourceRegistry
+		parsed.Host = match[1]
+		if parsed.Host == "" {
+			parsed.Host = DefaultRegistryHost
+		}
+		parsed.Namespace, parsed.Name, parsed.Provider = match[2], match[3], match[4]
+		return parsed
+
+	case getter == "" && (strings.HasPrefix(address, "github.com/") || strings.HasPrefix(address, "bitbucket.org/")):
+		host, repoPath, _ := strings.Cut(address, "/")
+		parsed.Kind = SourceGitHub
+		if host == "bitbucket.org" {
+			parsed.Kind = SourceBitbucket
+		}
+		parsed.Host = host
+		parsed.Namespace, parsed.Name = splitRepoPath(repoPath)
+		parsed.URL = "https://" + address
+		return parsed
+
+	case getter == "" && strings.HasPrefix(address, "git@"):
+		getter = "git"
+	}
+
+	// scp-like SSH addresses, e.g. git@github.com:org/repo.git
+	if strings.HasPrefix(address, "git@") && !strings.Contains(address, "://") {
+		address = "ssh://" + strings.Replace(address, ":", "/", 1)
+	}
+
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" {
+		// Scheme-less S3 and GCS addresses are detected by their hostname
+		if u, err = url.Parse("https://" + address); err != nil || !strings.Contains(u.Host, ".") {
+			return parsed
+		}
+		if getter == "" {
+			switch {
+			case strings.HasSuffix(u.Host, "amazonaws.com"):
+				getter = "s3"
+			case u.Host == "www.googleapis.com" || u.Host == "storage.googleapis.com":
+				getter = "gcs"
+			default:
+				return parsed
+			}
+		}
+	}
+
+	parsed.Host = u.Hostname()
+	parsed.URL = u.String()
+	parsed.Namespace, parsed.Name = splitRepoPath(strings.TrimPrefix(u.Path, "/"))
+	parsed.ArchiveFormat = query.Get("archive")
+
+	switch getter {
+	case "git":
+		parsed.Kind = SourceGit
+	case "hg":
+		parsed.Kind = SourceMercurial
+	case "s3":
+		parsed.Kind = SourceS3
+	case "gcs":
+		parsed.Kind = SourceGCS
+	case "", "http", "https":
+		if u.Scheme == "http" || u.Scheme == "https" {
+			parsed.Kind = SourceHTTP
+		}
+	}
+
+	if parsed.Kind == SourceHTTP || parsed.Kind == SourceS3 || parsed.Kind == SourceGCS {
+		if parsed.ArchiveFormat == "" {
+			parsed.ArchiveFormat = archiveFormat(parsed.Name)
+		}
+		parsed.Name = strings.TrimSuffix(parsed.Name, "."+archiveFormat(parsed.Name))
+	}
+
+	return parsed
+}
+
+// pinnedRefPattern matches refs that name a fixed revision: a full SHA-1 or SHA-256
+// commit, or a version tag such as "v1.2.0" or "1.2"
+var pinnedRefPattern = regexp.MustCompile(`^(?:[0-9a-fA-F]{40}|[0-9a-fA-F]{64}|v?\d+(?:\.\d+)*(?:[-+][0-9A-Za-z.+-]+)?)$`)
+
+// IsPinnedRef reports whether a version control ref selects a fixed revision rather
+// than a branch. Branches cannot be told apart from tags without the repository, so
+// refs that look like versions are taken to be tags.
+func IsPinnedRef(ref string) bool {
+	return pinnedRefPattern.MatchString(ref)
+}
+
+// IsLocalSource reports whether a module source refers to a local filesystem path
+func IsLocalSource(source string) bool {
+	return strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")
+}
+
+// QueryString renders the source's query parameters in their original order
+func (m ModuleSource) QueryString() string {
+	params := make([]string, 0, len(m.Query))
+	for _, param := range m.Query {
+		params = append(params, url.QueryEscape(param.Name)+"="+url.QueryEscape(param.Value))
+	}
+	return strings.Join(params, "&")
+}
+
+// splitSourceAddress separates a go-getter source address into the address itself,
+// the "//subdir" path and the query parameters
+func splitSourceAddress(source string) (string, string, url.Values) {
+	address, rawQuery, _ := strings.Cut(source, "?")
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		query = url.Values{}
+	}
+
+	// Skip over any "scheme://" so its slashes are not mistaken for a subdirectory
+	searchFrom := 0
+	if idx := strings.Index(address, "://"); idx != -1 {
+		searchFrom = idx + 3
+	}
+	var subdir string
+	if idx := strings.Index(address[searchFrom:], "//"); idx != -1 {
+		subdir = strings.Trim(address[searchFrom+idx+2:], "/")
+		address = address[:searchFrom+idx]
+	}
+
+	return address, subdir, query
+}
+
+// sourceParams converts query parameters into a deterministic slice
+func sourceParams(query url.Values) []SourceParam {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []SourceParam
+	for _, name := range names {
+		for _, value := range query[name] {
+			params = append(params, SourceParam{Name: name, Value: value})
+		}
+	}
+	return params
+}
+
+// splitRepoPath splits "a/b/c.git" into the namespace "a/b" and the name "c"
+func splitRepoPath(repoPath string) (string, string) {
+	repoPath = strings.Trim(repoPath, "/")
+	namespace, name := path.Split(repoPath)
+	return strings.TrimSuffix(namespace, "/"), strings.TrimSuffix(name, ".git")
+}
+
+// isVCSHost reports whether a registry-shaped address is actually a VCS shorthand
+func isVCSHost(host string) bool {
+	return host == "github.com" || host == "bitbucket.org"
+}
+
+// archiveFormat returns the archive format implied by a file name's extension
+func archiveFormat(name string) string {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(name, "."+ext) {
+			return ext
+		}
+	}
+	return ""
+}
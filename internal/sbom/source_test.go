@@ -0,0 +1,194 @@
+package sbom
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected ModuleSource
+	}{
+		{
+			name:     "local path",
+			source:   "./modules/vpc",
+			expected: ModuleSource{Kind: SourceLocal, Name: "vpc"},
+		},
+		{
+			name:     "parent local path",
+			source:   "../shared/",
+			expected: ModuleSource{Kind: SourceLocal, Name: "shared"},
+		},
+		{
+			name:   "public registry",
+			source: "terraform-aws-modules/vpc/aws",
+			expected: ModuleSource{
+				Kind: SourceRegistry, Host: DefaultRegistryHost,
+				Namespace: "terraform-aws-modules", Name: "vpc", Provider: "aws",
+			},
+		},
+		{
+			name:   "private registry with submodule",
+			source: "app.terraform.io/example-corp/k8s-cluster/azurerm//modules/nodes",
+			expected: ModuleSource{
+				Kind: SourceRegistry, Host: "app.terraform.io",
+				Namespace: "example-corp", Name: "k8s-cluster", Provider: "azurerm",
+				Subdirectory: "modules/nodes",
+			},
+		},
+		{
+			name:   "github shorthand",
+			source: "github.com/hashicorp/example//modules/vpc?ref=v1.2.0",
+			expected: ModuleSource{
+				Kind: SourceGitHub, Host: "github.com", Namespace: "hashicorp", Name: "example",
+				URL: "https://github.com/hashicorp/example", Subdirectory: "modules/vpc", Ref: "v1.2.0",
+				Query: []SourceParam{{Name: "ref", Value: "v1.2.0"}},
+			},
+		},
+		{
+			name:   "bitbucket shorthand",
+			source: "bitbucket.org/hashicorp/terraform-consul-aws",
+			expected: ModuleSource{
+				Kind: SourceBitbucket, Host: "bitbucket.org", Namespace: "hashicorp", Name: "terraform-consul-aws",
+				URL: "https://bitbucket.org/hashicorp/terraform-consul-aws",
+			},
+		},
+		{
+			name:   "forced git over https",
+			source: "git::https://example.com/infra/vpc.git?ref=v1.2.0&depth=1",
+			expected: ModuleSource{
+				Kind: SourceGit, Host: "example.com", Namespace: "infra", Name: "vpc",
+				URL: "https://example.com/infra/vpc.git", Ref: "v1.2.0",
+				Query: []SourceParam{{Name: "depth", Value: "1"}, {Name: "ref", Value: "v1.2.0"}},
+			},
+		},
+		{
+			name:   "scp-like git",
+			source: "git@github.com:hashicorp/example.git//modules/core",
+			expected: ModuleSource{
+				Kind: SourceGit, Host: "github.com", Namespace: "hashicorp", Name: "example",
+				URL: "ssh://git@github.com/hashicorp/example.git", Subdirectory: "modules/core",
+			},
+		},
+		{
+			name:   "mercurial",
+			source: "hg::http://example.com/vpc.hg?ref=default",
+			expected: ModuleSource{
+				Kind: SourceMercurial, Host: "example.com", Name: "vpc.hg",
+				URL: "http://example.com/vpc.hg", Ref: "default",
+				Query: []SourceParam{{Name: "ref", Value: "default"}},
+			},
+		},
+		{
+			name:   "http archive",
+			source: "https://example.com/modules/vpc-module.tar.gz",
+			expected: ModuleSource{
+				Kind: SourceHTTP, Host: "example.com", Namespace: "modules", Name: "vpc-module",
+				URL: "https://example.com/modules/vpc-module.tar.gz", ArchiveFormat: "tar.gz",
+			},
+		},
+		{
+			name:   "http with explicit archive format",
+			source: "https://example.com/download?archive=zip",
+			expected: ModuleSource{
+				Kind: SourceHTTP, Host: "example.com", Name: "download",
+				URL: "https://example.com/download", ArchiveFormat: "zip",
+				Query: []SourceParam{{Name: "archive", Value: "zip"}},
+			},
+		},
+		{
+			name:   "forced s3",
+			source: "s3::https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip",
+			expected: ModuleSource{
+				Kind: SourceS3, Host: "s3-eu-west-1.amazonaws.com", Namespace: "examplecorp-terraform-modules", Name: "vpc",
+				URL: "https://s3-eu-west-1.amazonaws.com/examplecorp-terraform-modules/vpc.zip", ArchiveFormat: "zip",
+			},
+		},
+		{
+			name:   "scheme-less s3",
+			source: "bucket.s3.amazonaws.com/vpc.zip",
+			expected: ModuleSource{
+				Kind: SourceS3, Host: "bucket.s3.amazonaws.com", Name: "vpc",
+				URL: "https://bucket.s3.amazonaws.com/vpc.zip", ArchiveFormat: "zip",
+			},
+		},
+		{
+			name:   "forced gcs",
+			source: "gcs::https://www.googleapis.com/storage/v1/modules/foomodule.tar.gz",
+			expected: ModuleSource{
+				Kind: SourceGCS, Host: "www.googleapis.com", Namespace: "storage/v1/modules", Name: "foomodule",
+				URL: "https://www.googleapis.com/storage/v1/modules/foomodule.tar.gz", ArchiveFormat: "tar.gz",
+			},
+		},
+		{
+			name:     "unrecognized source",
+			source:   "not a source",
+			expected: ModuleSource{Kind: SourceUnknown},
+		},
+		{
+			name:     "empty source",
+			source:   "",
+			expected: ModuleSource{Kind: SourceUnknown},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := ParseSource(test.source)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("ParseSource(%q) = %+v, want %+v", test.source, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestModuleSourceQueryString(t *testing.T) {
+	source := ModuleSource{Query: []SourceParam{{Name: "depth", Value: "1"}, {Name: "ref", Value: "feature/a b"}}}
+	expected := "depth=1&ref=feature%2Fa+b"
+	if result := source.QueryString(); result != expected {
+		t.Errorf("QueryString() = %q, want %q", result, expected)
+	}
+}
+
+func TestIsLocalSource(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected bool
+	}{
+		{"./modules/vpc", true},
+		{"../shared", true},
+		{"terraform-aws-modules/vpc/aws", false},
+		{"github.com/hashicorp/example", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if result := IsLocalSource(test.source); result != test.expected {
+			t.Errorf("IsLocalSource(%q) = %v, want %v", test.source, result, test.expected)
+		}
+	}
+}
+
+func TestIsPinnedRef(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected bool
+	}{
+		{"v1.2.0", true},
+		{"1.2", true},
+		{"2.0.0-rc.1", true},
+		{"0123456789abcdef0123456789abcdef01234567", true},
+		{"abc1234", false},
+		{"main", false},
+		{"release/v1", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		if result := IsPinnedRef(test.ref); result != test.expected {
+			t.Errorf("IsPinnedRef(%q) = %v, want %v", test.ref, result, test.expected)
+		}
+	}
+}
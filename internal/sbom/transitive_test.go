@@ -0,0 +1,248 @@
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates each file below dir, creating parent directories as needed
+func writeFiles(t testing.TB, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestGenerateTransitive(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_transitive_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"main.tf": `
+module "network" {
+  source = "./modules/network"
+}
+`,
+		"modules/network/main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "~> 5.0"
+}
+`,
+		".terraform/modules/network.vpc/main.tf": `
+terraform {
+  required_providers {
+    aws = {
+      source = "hashicorp/aws"
+    }
+  }
+}
+
+module "flow_logs" {
+  source = "./modules/flow-logs"
+}
+`,
+		".terraform/modules/network.vpc/modules/flow-logs/main.tf": `
+resource "aws_flow_log" "this" {}
+`,
+		".terraform/modules/modules.json": `{"Modules":[
+  {"Key":"","Source":"","Dir":"."},
+  {"Key":"network","Source":"./modules/network","Dir":"modules/network"},
+  {"Key":"network.vpc","Source":"registry.terraform.io/terraform-aws-modules/vpc/aws","Version":"5.1.2","Dir":".terraform/modules/network.vpc"},
+  {"Key":"network.vpc.flow_logs","Source":"./modules/flow-logs","Dir":".terraform/modules/network.vpc/modules/flow-logs"}
+]}`,
+	})
+
+	absDir, _ := filepath.Abs(tmpDir)
+
+	t.Run("records the module tree", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Transitive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+
+		expected := []struct {
+			name   string
+			path   string
+			parent string
+			depth  int
+		}{
+			// Sorted by the file making the call: .terraform/modules/..., main.tf, modules/network/main.tf
+			{"flow_logs", "module.network.module.vpc.module.flow_logs", "module.network.module.vpc", 3},
+			{"network", "module.network", "", 1},
+			{"vpc", "module.network.module.vpc", "module.network", 2},
+		}
+		if len(result.Modules) != len(expected) {
+			t.Fatalf("len(result.Modules) = %v, want %v", len(result.Modules), len(expected))
+		}
+		for i, want := range expected {
+			module := result.Modules[i]
+			if module.Name != want.name || module.Path != want.path || module.Parent != want.parent || module.Depth != want.depth {
+				t.Errorf("Modules[%d] = {%s %s %s %d}, want %+v", i, module.Name, module.Path, module.Parent, module.Depth, want)
+			}
+			if module.Root != absDir {
+				t.Errorf("Modules[%d].Root = %q, want %q", i, module.Root, absDir)
+			}
+		}
+
+		// Without ResolveInstalled the declared constraint is kept
+		if result.Modules[2].Version != "~> 5.0" {
+			t.Errorf("vpc.Version = %q, want '~> 5.0'", result.Modules[2].Version)
+		}
+
+		// Providers required by called modules, including those implied by resources,
+		// are part of the inventory
+		expectedDirs := []string{
+			filepath.Join(absDir, ".terraform", "modules", "network.vpc"),
+			filepath.Join(absDir, ".terraform", "modules", "network.vpc", "modules", "flow-logs"),
+		}
+		if len(result.Providers) != len(expectedDirs) {
+			t.Fatalf("len(result.Providers) = %v, want %v", len(result.Providers), len(expectedDirs))
+		}
+		for i, dir := range expectedDirs {
+			if result.Providers[i].Name != "aws" || result.Providers[i].Directory != dir {
+				t.Errorf("Providers[%d] = %s in %s, want aws in %s", i, result.Providers[i].Name, result.Providers[i].Directory, dir)
+			}
+		}
+	})
+
+	t.Run("recursive scan does not duplicate called modules", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Transitive: true})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+		if len(result.Modules) != 3 {
+			t.Errorf("len(result.Modules) = %v, want 3", len(result.Modules))
+		}
+	})
+
+	t.Run("non-transitive scan only records direct calls", func(t *testing.T) {
+		result, err := GenerateWithOptions(tmpDir, Options{})
+		if err != nil {
+			t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+		}
+		if len(result.Modules) != 1 || result.Modules[0].Depth != 1 || result.Modules[0].Parent != "" {
+			t.Errorf("Modules = %+v, want only the direct network call", result.Modules)
+		}
+	})
+}
+
+func TestGenerateTransitiveCycle(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_transitive_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeFiles(t, tmpDir, map[string]string{
+		"main.tf":   `module "a" { source = "./a" }`,
+		"a/main.tf": `module "b" { source = "../b" }`,
+		"b/main.tf": `module "a" { source = "../a" }`,
+	})
+
+	result, err := GenerateWithOptions(tmpDir, Options{Transitive: true})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+	}
+
+	// root -> a -> b, and b's call back into a is recorded but not followed
+	if len(result.Modules) != 3 {
+		t.Errorf("len(result.Modules) = %v, want 3", len(result.Modules))
+	}
+}
+
+func TestGenerateTransitiveRootsIndependentOfOrder(t *testing.T) {
+	// The same layout with the called module's directory sorting before and after the
+	// directory calling it
+	layouts := []struct{ modules, stacks string }{
+		{"modules", "stacks"},
+		{"z_modules", "a_stacks"},
+	}
+
+	for _, layout := range layouts {
+		t.Run(layout.modules, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "test_transitive_*")
+			if err != nil {
+				t.Fatalf("failed to create temp directory: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			writeFiles(t, tmpDir, map[string]string{
+				layout.stacks + "/app/main.tf": `
+module "net" {
+  source = "../../` + layout.modules + `/net"
+}
+`,
+				layout.modules + "/net/main.tf": `
+module "vpc" {
+  source  = "terraform-aws-modules/vpc/aws"
+  version = "5.1.2"
+}
+`,
+			})
+
+			result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Transitive: true, Reproducible: true})
+			if err != nil {
+				t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+			}
+
+			// The called module is part of the app tree rather than a root of its own
+			app := layout.stacks + "/app"
+			if len(result.Modules) != 2 {
+				t.Fatalf("len(result.Modules) = %v, want 2", len(result.Modules))
+			}
+			for _, module := range result.Modules {
+				if module.Root != app {
+					t.Errorf("%s.Root = %q, want %q", module.Path, module.Root, app)
+				}
+			}
+
+			counts := make(map[string]int)
+			for _, configuration := range result.Configurations {
+				counts[configuration.Path] = len(configuration.Modules)
+			}
+			if counts[app] != 2 || counts[layout.modules+"/net"] != 0 {
+				t.Errorf("module calls per configuration = %v, want 2 under %s", counts, app)
+			}
+		})
+	}
+}
+
+func TestGenerateTransitiveCycleWithoutRoot(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test_transitive_*")
+	if err != nil {
+		t.Fatalf("failed to create temp directory: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Every directory is called by another, so none is an obvious root
+	writeFiles(t, tmpDir, map[string]string{
+		"a/main.tf": `module "b" { source = "../b" }`,
+		"b/main.tf": `module "a" { source = "../a" }`,
+	})
+
+	result, err := GenerateWithOptions(tmpDir, Options{Recursive: true, Transitive: true, Reproducible: true})
+	if err != nil {
+		t.Fatalf("GenerateWithOptions() = %v, want nil", err)
+	}
+
+	// The first directory is walked as a root and the other as its child
+	if len(result.Modules) != 2 {
+		t.Fatalf("len(result.Modules) = %v, want 2", len(result.Modules))
+	}
+	for _, module := range result.Modules {
+		if module.Root != "a" {
+			t.Errorf("%s.Root = %q, want a", module.Path, module.Root)
+		}
+	}
+}
@@ -6,18 +6,150 @@ import (
 
 // ModuleInfo represents information about a Terraform module
 type ModuleInfo struct {
-	Name     string `json:"name" xml:"name"`
+	Name              string        `json:"name" xml:"name"`
+	Source            string        `json:"source" xml:"source"`
+	Version           string        `json:"version" xml:"version"`
+	Location          string        `json:"location" xml:"location"`
+	Filename          string        `json:"filename" xml:"filename"`
+	PURL              string        `json:"purl,omitempty" xml:"purl,omitempty"`
+	ParsedSource      *ModuleSource `json:"parsed_source,omitempty" xml:"parsed_source,omitempty"`
+	VersionConstraint string        `json:"version_constraint,omitempty" xml:"version_constraint,omitempty"`
+	InstalledDir      string        `json:"installed_dir,omitempty" xml:"installed_dir,omitempty"`
+	Root              string        `json:"root,omitempty" xml:"root,omitempty"`
+	Path              string        `json:"path,omitempty" xml:"path,omitempty"`
+	Parent            string        `json:"parent,omitempty" xml:"parent,omitempty"`
+	Depth             int           `json:"depth,omitempty" xml:"depth,omitempty"`
+	Line              int           `json:"line,omitempty" xml:"line,omitempty"`
+	Column            int           `json:"column,omitempty" xml:"column,omitempty"`
+	FilePath          string        `json:"file_path,omitempty" xml:"file_path,omitempty"`
+}
+
+// ProviderInfo represents a provider required by a Terraform configuration directory
+type ProviderInfo struct {
+	Name               string   `json:"name" xml:"name"`
+	Source             string   `json:"source" xml:"source"`
+	VersionConstraints []string `json:"version_constraints,omitempty" xml:"version_constraints>constraint,omitempty"`
+	Location           string   `json:"location,omitempty" xml:"location,omitempty"`
+	Filename           string   `json:"filename,omitempty" xml:"filename,omitempty"`
+	Directory          string   `json:"directory" xml:"directory"`
+	Version            string   `json:"version,omitempty" xml:"version,omitempty"`
+	Hashes             []string `json:"hashes,omitempty" xml:"hashes>hash,omitempty"`
+	LockFile           string   `json:"lock_file,omitempty" xml:"lock_file,omitempty"`
+	Line               int      `json:"line,omitempty" xml:"line,omitempty"`
+	Column             int      `json:"column,omitempty" xml:"column,omitempty"`
+	FilePath           string   `json:"file_path,omitempty" xml:"file_path,omitempty"`
+}
+
+// LockIssue reports a provider that is required but not locked, or locked but not required
+type LockIssue struct {
+	LockFile string `json:"lock_file" xml:"lock_file"`
+	Provider string `json:"provider" xml:"provider"`
+	Issue    string `json:"issue" xml:"issue"`
+}
+
+// ResourceInfo represents a managed resource or data source declared in a configuration directory
+type ResourceInfo struct {
+	Address        string `json:"address" xml:"address"`
+	Mode           string `json:"mode" xml:"mode"`
+	Type           string `json:"type" xml:"type"`
+	Name           string `json:"name" xml:"name"`
+	Provider       string `json:"provider" xml:"provider"`
+	ProviderSource string `json:"provider_source" xml:"provider_source"`
+	Location       string `json:"location" xml:"location"`
+	Filename       string `json:"filename" xml:"filename"`
+	Directory      string `json:"directory" xml:"directory"`
+}
+
+// ResourceSummary counts the resources and data sources that map to a provider
+type ResourceSummary struct {
+	Provider         string `json:"provider" xml:"provider"`
+	ManagedResources int    `json:"managed_resources" xml:"managed_resources"`
+	DataResources    int    `json:"data_resources" xml:"data_resources"`
+}
+
+// TerraformCoreInfo records the required_version constraints declared in a configuration directory
+type TerraformCoreInfo struct {
+	Directory   string   `json:"directory" xml:"directory"`
+	Constraints []string `json:"required_version" xml:"required_version>constraint"`
+}
+
+// CoreConflict reports two directories whose required_version constraints cannot both be satisfied
+type CoreConflict struct {
+	Directory             string `json:"directory" xml:"directory"`
+	Constraint            string `json:"constraint" xml:"constraint"`
+	ConflictingDirectory  string `json:"conflicting_directory" xml:"conflicting_directory"`
+	ConflictingConstraint string `json:"conflicting_constraint" xml:"conflicting_constraint"`
+}
+
+// Diagnostic is a problem found while scanning a directory. Directories with errors
+// are skipped unless the scan is strict, in which case the first error aborts it.
+type Diagnostic struct {
+	Severity  string `json:"severity" xml:"severity"`
+	Directory string `json:"directory" xml:"directory"`
+	Filename  string `json:"filename,omitempty" xml:"filename,omitempty"`
+	Line      int    `json:"line,omitempty" xml:"line,omitempty"`
+	Message   string `json:"message" xml:"message"`
+}
+
+// Vulnerability is a known advisory affecting the version of a module or provider
+// recorded in the SBOM
+type Vulnerability struct {
+	ID            string   `json:"id" xml:"id"`
+	Aliases       []string `json:"aliases,omitempty" xml:"aliases>alias,omitempty"`
+	Summary       string   `json:"summary,omitempty" xml:"summary,omitempty"`
+	Severity      string   `json:"severity,omitempty" xml:"severity,omitempty"`
+	ComponentType string   `json:"component_type" xml:"component_type"`
+	Component     string   `json:"component" xml:"component"`
+	PURL          string   `json:"purl,omitempty" xml:"purl,omitempty"`
+	Version       string   `json:"version" xml:"version"`
+	FixedVersions []string `json:"fixed_versions,omitempty" xml:"fixed_versions>version,omitempty"`
+	References    []string `json:"references,omitempty" xml:"references>url,omitempty"`
+	Location      string   `json:"location,omitempty" xml:"location,omitempty"`
+	Filename      string   `json:"filename,omitempty" xml:"filename,omitempty"`
+	Line          int      `json:"line,omitempty" xml:"line,omitempty"`
+	Column        int      `json:"column,omitempty" xml:"column,omitempty"`
+	FilePath      string   `json:"file_path,omitempty" xml:"file_path,omitempty"`
+	Status        string   `json:"status" xml:"status"`
+	Justification string   `json:"justification,omitempty" xml:"justification,omitempty"`
+	Impact        string   `json:"impact_statement,omitempty" xml:"impact_statement,omitempty"`
+}
+
+// PolicyViolation is a module call that breaks a rule of the policy it was checked against
+type PolicyViolation struct {
+	Rule     string `json:"rule" xml:"rule"`
+	Module   string `json:"module" xml:"module"`
+	Address  string `json:"address,omitempty" xml:"address,omitempty"`
 	Source   string `json:"source" xml:"source"`
-	Version  string `json:"version" xml:"version"`
-	Location string `json:"location" xml:"location"`
-	Filename string `json:"filename" xml:"filename"`
+	Filename string `json:"filename,omitempty" xml:"filename,omitempty"`
+	Line     int    `json:"line,omitempty" xml:"line,omitempty"`
+	Column   int    `json:"column,omitempty" xml:"column,omitempty"`
+	FilePath string `json:"file_path,omitempty" xml:"file_path,omitempty"`
+	Message  string `json:"message" xml:"message"`
+}
+
+// Configuration is a directory of Terraform files found by the scan, with the module
+// calls made from it
+type Configuration struct {
+	Path    string       `json:"path" xml:"path"`
+	Kind    string       `json:"kind" xml:"kind"`
+	Modules []ModuleInfo `json:"modules,omitempty" xml:"Modules>Module,omitempty"`
 }
 
 // SBOM represents a Software Bill of Materials for Terraform configurations
 type SBOM struct {
-	XMLName   xml.Name     `json:"-" xml:"SBOM"`
-	Version   string       `json:"version" xml:"version,attr"`
-	Generated string       `json:"generated" xml:"generated,attr"`
-	Tool      string       `json:"tool" xml:"tool,attr"`
-	Modules   []ModuleInfo `json:"modules" xml:"Modules>Module"`
+	XMLName          xml.Name            `json:"-" xml:"SBOM"`
+	Version          string              `json:"version" xml:"version,attr"`
+	Generated        string              `json:"generated" xml:"generated,attr"`
+	Tool             string              `json:"tool" xml:"tool,attr"`
+	Modules          []ModuleInfo        `json:"modules" xml:"Modules>Module"`
+	Configurations   []Configuration     `json:"configurations,omitempty" xml:"Configurations>Configuration,omitempty"`
+	Providers        []ProviderInfo      `json:"providers,omitempty" xml:"Providers>Provider,omitempty"`
+	LockIssues       []LockIssue         `json:"lock_issues,omitempty" xml:"LockIssues>Issue,omitempty"`
+	Resources        []ResourceInfo      `json:"resources,omitempty" xml:"Resources>Resource,omitempty"`
+	ResourceSummary  []ResourceSummary   `json:"resource_summary,omitempty" xml:"ResourceSummary>Provider,omitempty"`
+	TerraformCore    []TerraformCoreInfo `json:"terraform_core,omitempty" xml:"TerraformCore>Requirement,omitempty"`
+	CoreConflicts    []CoreConflict      `json:"core_conflicts,omitempty" xml:"CoreConflicts>Conflict,omitempty"`
+	Diagnostics      []Diagnostic        `json:"diagnostics,omitempty" xml:"Diagnostics>Diagnostic,omitempty"`
+	Vulnerabilities  []Vulnerability     `json:"vulnerabilities,omitempty" xml:"Vulnerabilities>Vulnerability,omitempty"`
+	PolicyViolations []PolicyViolation   `json:"policy_violations,omitempty" xml:"PolicyViolations>Violation,omitempty"`
 }
@@ -3,6 +3,7 @@ package sbom
 import (
 	"encoding/json"
 	"encoding/xml"
+	"reflect"
 	"testing"
 )
 
@@ -13,6 +14,16 @@ func TestModuleInfoSerialization(t *testing.T) {
 		Version:  "1.0.0",
 		Location: "Module call at /project/main.tf:10",
 		Filename: "/project/main.tf",
+		ParsedSource: &ModuleSource{
+			Kind:         SourceGitHub,
+			Host:         "github.com",
+			Namespace:    "example",
+			Name:         "test-module",
+			URL:          "https://github.com/example/test-module",
+			Subdirectory: "modules/core",
+			Ref:          "v1.0.0",
+			Query:        []SourceParam{{Name: "ref", Value: "v1.0.0"}},
+		},
 	}
 
 	// Test JSON serialization
@@ -43,6 +54,9 @@ func TestModuleInfoSerialization(t *testing.T) {
 		if unmarshaled.Filename != moduleInfo.Filename {
 			t.Errorf("Filename = %v, want %v", unmarshaled.Filename, moduleInfo.Filename)
 		}
+		if !reflect.DeepEqual(unmarshaled.ParsedSource, moduleInfo.ParsedSource) {
+			t.Errorf("ParsedSource = %+v, want %+v", unmarshaled.ParsedSource, moduleInfo.ParsedSource)
+		}
 	})
 
 	// Test XML serialization
@@ -73,6 +87,9 @@ func TestModuleInfoSerialization(t *testing.T) {
 		if unmarshaled.Filename != moduleInfo.Filename {
 			t.Errorf("Filename = %v, want %v", unmarshaled.Filename, moduleInfo.Filename)
 		}
+		if !reflect.DeepEqual(unmarshaled.ParsedSource, moduleInfo.ParsedSource) {
+			t.Errorf("ParsedSource = %+v, want %+v", unmarshaled.ParsedSource, moduleInfo.ParsedSource)
+		}
 	})
 }
 
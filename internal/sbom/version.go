@@ -0,0 +1,256 @@
+package sbom
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version as used in Terraform version constraints. Missing
+// minor and patch segments are zero.
+type Version struct {
+	Segments   [3]int
+	Prerelease string
+}
+
+// versionPattern matches a version with up to three segments, an optional prerelease
+// and optional build metadata, which is ignored
+var versionPattern = regexp.MustCompile(`^v?(\d+)(?:\.(\d+))?(?:\.(\d+))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+// constraintPattern matches a single constraint such as ">= 1.2.0" or "~> 1.5"
+var constraintPattern = regexp.MustCompile(`^(=|!=|>=|<=|>|<|~>)?\s*(\S+)$`)
+
+// ParseVersion parses a version such as "1.5.7" or "v1.6.0-beta1"
+func ParseVersion(version string) (Version, error) {
+	v, _, err := parseVersion(version)
+	return v, err
+}
+
+// parseVersion parses a version and also returns how many segments were given, which
+// determines the upper bound of a pessimistic constraint
+func parseVersion(version string) (Version, int, error) {
+	match := versionPattern.FindStringSubmatch(strings.TrimSpace(version))
+	if match == nil {
+		return Version{}, 0, fmt.Errorf("invalid version %q", version)
+	}
+
+	var v Version
+	count := 0
+	for i := 0; i < 3; i++ {
+		if match[i+1] == "" {
+			break
+		}
+		segment, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return Version{}, 0, fmt.Errorf("invalid version %q: %w", version, err)
+		}
+		v.Segments[i] = segment
+		count++
+	}
+	v.Prerelease = match[4]
+	return v, count, nil
+}
+
+// String formats the version with all three segments
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Segments[0], v.Segments[1], v.Segments[2])
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 when v is lower than, equal to or greater than other.
+// A prerelease sorts before the release it precedes.
+func (v Version) Compare(other Version) int {
+	for i := range v.Segments {
+		if v.Segments[i] != other.Segments[i] {
+			if v.Segments[i] < other.Segments[i] {
+				return -1
+			}
+			return 1
+		}
+	}
+
+	switch {
+	case v.Prerelease == other.Prerelease:
+		return 0
+	case v.Prerelease == "":
+		return 1
+	case other.Prerelease == "":
+		return -1
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// comparePrerelease compares dot-separated prerelease identifiers, numerically when
+// both identifiers are numbers
+func comparePrerelease(a, b string) int {
+	left, right := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(left) && i < len(right); i++ {
+		if left[i] == right[i] {
+			continue
+		}
+		x, errX := strconv.Atoi(left[i])
+		y, errY := strconv.Atoi(right[i])
+		switch {
+		case errX == nil && errY == nil:
+			if x < y {
+				return -1
+			}
+			return 1
+		case errX == nil:
+			return -1
+		case errY == nil:
+			return 1
+		case left[i] < right[i]:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	switch {
+	case len(left) < len(right):
+		return -1
+	case len(left) > len(right):
+		return 1
+	}
+	return 0
+}
+
+// versionBound is one end of a VersionRange. An unset bound is unbounded.
+type versionBound struct {
+	version   Version
+	inclusive bool
+	set       bool
+}
+
+// VersionRange is the set of versions allowed by one or more version constraints
+type VersionRange struct {
+	lower    versionBound
+	upper    versionBound
+	excluded []Version
+}
+
+// ParseConstraint parses a comma-separated list of version constraints, as written in
+// required_version or a provider version argument, into the range they allow
+func ParseConstraint(constraint string) (VersionRange, error) {
+	var r VersionRange
+	for _, part := range strings.Split(constraint, ",") {
+		match := constraintPattern.FindStringSubmatch(strings.TrimSpace(part))
+		if match == nil {
+			return VersionRange{}, fmt.Errorf("invalid version constraint %q", constraint)
+		}
+
+		v, count, err := parseVersion(match[2])
+		if err != nil {
+			return VersionRange{}, fmt.Errorf("invalid version constraint %q: %w", constraint, err)
+		}
+
+		var c VersionRange
+		switch match[1] {
+		case "", "=":
+			c.lower = versionBound{version: v, inclusive: true, set: true}
+			c.upper = versionBound{version: v, inclusive: true, set: true}
+		case "!=":
+			c.excluded = []Version{v}
+		case ">":
+			c.lower = versionBound{version: v, set: true}
+		case ">=":
+			c.lower = versionBound{version: v, inclusive: true, set: true}
+		case "<":
+			c.upper = versionBound{version: v, set: true}
+		case "<=":
+			c.upper = versionBound{version: v, inclusive: true, set: true}
+		case "~>":
+			// Only the rightmost given segment may increase
+			upper := Version{}
+			if count < 3 {
+				upper.Segments[0] = v.Segments[0] + 1
+			} else {
+				upper.Segments[0] = v.Segments[0]
+				upper.Segments[1] = v.Segments[1] + 1
+			}
+			c.lower = versionBound{version: v, inclusive: true, set: true}
+			c.upper = versionBound{version: upper, set: true}
+		}
+		r = r.Intersect(c)
+	}
+	return r, nil
+}
+
+// Intersect returns the range of versions allowed by both r and other
+func (r VersionRange) Intersect(other VersionRange) VersionRange {
+	result := VersionRange{lower: r.lower, upper: r.upper}
+	result.excluded = append(append([]Version{}, r.excluded...), other.excluded...)
+
+	if other.lower.set {
+		cmp := 1
+		if r.lower.set {
+			cmp = other.lower.version.Compare(r.lower.version)
+		}
+		if cmp > 0 || (cmp == 0 && !other.lower.inclusive) {
+			result.lower = other.lower
+		}
+	}
+	if other.upper.set {
+		cmp := -1
+		if r.upper.set {
+			cmp = other.upper.version.Compare(r.upper.version)
+		}
+		if cmp < 0 || (cmp == 0 && !other.upper.inclusive) {
+			result.upper = other.upper
+		}
+	}
+	return result
+}
+
+// IsEmpty reports whether no version satisfies the range
+func (r VersionRange) IsEmpty() bool {
+	if !r.lower.set || !r.upper.set {
+		return false
+	}
+
+	cmp := r.lower.version.Compare(r.upper.version)
+	switch {
+	case cmp > 0:
+		return true
+	case cmp < 0:
+		return false
+	}
+
+	// A single allowed version
+	if !r.lower.inclusive || !r.upper.inclusive {
+		return true
+	}
+	for _, excluded := range r.excluded {
+		if excluded.Compare(r.lower.version) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains reports whether version satisfies the range
+func (r VersionRange) Contains(version Version) bool {
+	if r.lower.set {
+		cmp := version.Compare(r.lower.version)
+		if cmp < 0 || (cmp == 0 && !r.lower.inclusive) {
+			return false
+		}
+	}
+	if r.upper.set {
+		cmp := version.Compare(r.upper.version)
+		if cmp > 0 || (cmp == 0 && !r.upper.inclusive) {
+			return false
+		}
+	}
+	for _, excluded := range r.excluded {
+		if version.Compare(excluded) == 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package sbom
+
+import "testing"
+
+func TestVersionCompare(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"1.5.7", "1.5.7", 0},
+		{"1.5", "1.5.0", 0},
+		{"v1.6.0", "1.6.0", 0},
+		{"1.5.7", "1.10.0", -1},
+		{"2.0.0", "1.99.99", 1},
+		{"1.6.0-beta1", "1.6.0", -1},
+		{"1.6.0-alpha", "1.6.0-beta", -1},
+		{"1.6.0-rc.2", "1.6.0-rc.10", -1},
+		{"1.6.0+build", "1.6.0", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" vs "+test.b, func(t *testing.T) {
+			a, err := ParseVersion(test.a)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) = %v, want nil", test.a, err)
+			}
+			b, err := ParseVersion(test.b)
+			if err != nil {
+				t.Fatalf("ParseVersion(%q) = %v, want nil", test.b, err)
+			}
+			if result := a.Compare(b); result != test.expected {
+				t.Errorf("Compare() = %v, want %v", result, test.expected)
+			}
+		})
+	}
+
+	t.Run("invalid version", func(t *testing.T) {
+		if _, err := ParseVersion("latest"); err == nil {
+			t.Error("ParseVersion(\"latest\") = nil, want error")
+		}
+	})
+}
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		allowed    []string
+		rejected   []string
+	}{
+		{"1.5.7", []string{"1.5.7"}, []string{"1.5.6", "1.5.8"}},
+		{"= 1.5.7", []string{"1.5.7"}, []string{"1.5.8"}},
+		{">= 1.9", []string{"1.9.0", "2.0.0"}, []string{"1.8.9"}},
+		{"> 1.9", []string{"1.9.1"}, []string{"1.9.0"}},
+		{"< 1.6", []string{"1.5.99"}, []string{"1.6.0"}},
+		{"<= 1.6", []string{"1.6.0"}, []string{"1.6.1"}},
+		{"~> 1.5.0", []string{"1.5.0", "1.5.9"}, []string{"1.4.9", "1.6.0"}},
+		{"~> 1.5", []string{"1.5.0", "1.9.0"}, []string{"1.4.0", "2.0.0"}},
+		{"~> 1", []string{"1.0.0", "1.99.0"}, []string{"2.0.0"}},
+		{">= 1.3, < 2.0, != 1.4.0", []string{"1.3.0", "1.5.0"}, []string{"1.4.0", "2.0.0"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			r, err := ParseConstraint(test.constraint)
+			if err != nil {
+				t.Fatalf("ParseConstraint(%q) = %v, want nil", test.constraint, err)
+			}
+			for _, version := range test.allowed {
+				v, _ := ParseVersion(version)
+				if !r.Contains(v) {
+					t.Errorf("Contains(%s) = false, want true", version)
+				}
+			}
+			for _, version := range test.rejected {
+				v, _ := ParseVersion(version)
+				if r.Contains(v) {
+					t.Errorf("Contains(%s) = true, want false", version)
+				}
+			}
+		})
+	}
+
+	t.Run("invalid constraint", func(t *testing.T) {
+		for _, constraint := range []string{"", ">= latest", "=> 1.0"} {
+			if _, err := ParseConstraint(constraint); err == nil {
+				t.Errorf("ParseConstraint(%q) = nil, want error", constraint)
+			}
+		}
+	})
+}
+
+func TestVersionRangeIsEmpty(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected bool
+	}{
+		{">= 1.9", "~> 1.5.0", true},
+		{">= 1.5", "~> 1.5.0", false},
+		{">= 1.6", "< 1.6", true},
+		{">= 1.6", "<= 1.6", false},
+		{"1.6.0", "!= 1.6.0", true},
+		{"> 1.6", "1.6.0", true},
+		{">= 1.0", ">= 1.9", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.a+" and "+test.b, func(t *testing.T) {
+			a, _ := ParseConstraint(test.a)
+			b, _ := ParseConstraint(test.b)
+			if result := a.Intersect(b).IsEmpty(); result != test.expected {
+				t.Errorf("Intersect().IsEmpty() = %v, want %v", result, test.expected)
+			}
+		})
+	}
+}
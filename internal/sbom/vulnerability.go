@@ -0,0 +1,13 @@
+package sbom
+
+// Vulnerability statuses, named after the OpenVEX statuses they correspond to
+const (
+	VulnerabilityAffected    = "affected"
+	VulnerabilityNotAffected = "not_affected"
+)
+
+// Suppressed reports whether a VEX statement marked the vulnerability as not affecting
+// the component
+func (v Vulnerability) Suppressed() bool {
+	return v.Status == VulnerabilityNotAffected
+}
@@ -0,0 +1,243 @@
+package vuln
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// Component types recorded on each vulnerability
+const (
+	ComponentModule   = "module"
+	ComponentProvider = "provider"
+)
+
+// Range types whose events can be compared as semantic versions
+const (
+	rangeSemver    = "SEMVER"
+	rangeEcosystem = "ECOSYSTEM"
+)
+
+// Match returns the advisories affecting the modules and providers of an SBOM, in
+// module order followed by provider order. Only components whose version is known
+// exactly are matched: modules pinned to a version or tag, and providers with a locked
+// or pinned version. A provider locked by the same lock file is reported once.
+func Match(db *Database, s *sbom.SBOM) []sbom.Vulnerability {
+	var findings []sbom.Vulnerability
+
+	for _, module := range s.Modules {
+		source := sbom.ParseSource(module.Source)
+		if module.ParsedSource != nil {
+			source = *module.ParsedSource
+		}
+		version := moduleVersion(module, source)
+		if version == "" {
+			continue
+		}
+
+		purl := module.PURL
+		if purl == "" {
+			purl = sbom.PackageURL(module.Source, module.Version)
+		}
+		for _, match := range db.match(moduleKeys(purl, source), version) {
+			finding := newFinding(match, ComponentModule, module.Name, purl, version)
+			finding.Location, finding.Filename, finding.FilePath = module.Location, module.Filename, module.FilePath
+			finding.Line, finding.Column = module.StartLine(), module.Column
+			findings = append(findings, finding)
+		}
+	}
+
+	type providerKey struct{ id, source, version, lock string }
+	seen := make(map[providerKey]bool)
+	for _, provider := range s.Providers {
+		version := providerVersion(provider)
+		if version == "" {
+			continue
+		}
+
+		source := sbom.ProviderSourceAddress(provider.Name, provider.Source)
+		lock := provider.LockFile
+		if lock == "" {
+			lock = provider.Directory
+		}
+		for _, match := range db.match(providerKeys(provider), version) {
+			key := providerKey{match.advisory.ID, source, version, lock}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			finding := newFinding(match, ComponentProvider, source, "", version)
+			finding.Location, finding.Filename, finding.FilePath = provider.Location, provider.Filename, provider.FilePath
+			finding.Line, finding.Column = provider.StartLine(), provider.Column
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+// advisoryMatch is an advisory affecting a component, with the versions that fix it
+type advisoryMatch struct {
+	advisory *Advisory
+	fixed    []string
+}
+
+// match returns the advisories affecting version of the package known by any of keys
+func (db *Database) match(keys []string, version string) []advisoryMatch {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+
+	var matches []advisoryMatch
+	seen := make(map[*Advisory]bool)
+	for _, key := range keys {
+		for _, advisory := range db.byPackage[key] {
+			if seen[advisory] {
+				continue
+			}
+			for _, affected := range advisory.Affected {
+				if !matchesAnyKey(affected.Package, wanted) || !affects(affected, version) {
+					continue
+				}
+				seen[advisory] = true
+				matches = append(matches, advisoryMatch{advisory: advisory, fixed: fixedVersions(affected)})
+				break
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool { return matches[i].advisory.ID < matches[j].advisory.ID })
+	return matches
+}
+
+// newFinding records an advisory match against a component
+func newFinding(match advisoryMatch, componentType, component, purl, version string) sbom.Vulnerability {
+	var references []string
+	for _, reference := range match.advisory.References {
+		references = append(references, reference.URL)
+	}
+	return sbom.Vulnerability{
+		ID:            match.advisory.ID,
+		Aliases:       match.advisory.Aliases,
+		Summary:       match.advisory.Summary,
+		Severity:      match.advisory.severity(),
+		ComponentType: componentType,
+		Component:     component,
+		PURL:          purl,
+		Version:       version,
+		FixedVersions: match.fixed,
+		References:    references,
+		Status:        sbom.VulnerabilityAffected,
+	}
+}
+
+// moduleVersion returns the exact version of a module call: its pinned version, or
+// for version control sources a ref that looks like a version tag
+func moduleVersion(module sbom.ModuleInfo, source sbom.ModuleSource) string {
+	if version, ok := sbom.ExactVersion(module.Version); ok {
+		return version
+	}
+	if source.Ref != "" {
+		if version, ok := sbom.ExactVersion(source.Ref); ok {
+			return version
+		}
+	}
+	return ""
+}
+
+// providerVersion returns the locked version of a provider, or the version its
+// constraints pin it to
+func providerVersion(provider sbom.ProviderInfo) string {
+	if version, ok := sbom.ExactVersion(provider.Version); ok {
+		return version
+	}
+	if version, ok := sbom.ExactVersion(provider.Constraint()); ok {
+		return version
+	}
+	return ""
+}
+
+// packageKeys returns the normalized names an advisory's affected package is indexed by
+func packageKeys(p Package) []string {
+	var keys []string
+	if p.PURL != "" {
+		keys = append(keys, purlBase(p.PURL))
+	}
+	if p.Name != "" {
+		keys = append(keys, strings.ToLower(p.Name))
+	}
+	return keys
+}
+
+// matchesAnyKey reports whether an affected package is known by one of the wanted keys
+func matchesAnyKey(p Package, wanted map[string]bool) bool {
+	for _, key := range packageKeys(p) {
+		if wanted[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// moduleKeys returns the names an advisory may use for a module: its package URL
+// without version or qualifiers, its registry address with and without host, or its
+// repository path
+func moduleKeys(purl string, source sbom.ModuleSource) []string {
+	var keys []string
+	if purl != "" {
+		keys = append(keys, purlBase(purl))
+	}
+
+	switch source.Kind {
+	case sbom.SourceRegistry:
+		address := source.Namespace + "/" + source.Name + "/" + source.Provider
+		keys = append(keys, address, source.Host+"/"+address)
+	default:
+		if u, err := url.Parse(source.URL); err == nil && u.Host != "" {
+			keys = append(keys, u.Hostname()+strings.TrimSuffix(u.Path, ".git"))
+		}
+	}
+
+	for i := range keys {
+		keys[i] = strings.ToLower(keys[i])
+	}
+	return keys
+}
+
+// providerKeys returns the names an advisory may use for a provider: its source
+// address with and without host, its plugin name, and the Go module path or GitHub
+// purl of its plugin repository
+func providerKeys(provider sbom.ProviderInfo) []string {
+	host, namespace, providerType := provider.SourceParts()
+	if providerType == "" {
+		return nil
+	}
+
+	plugin := "terraform-provider-" + providerType
+	keys := []string{
+		host + "/" + namespace + "/" + providerType,
+		namespace + "/" + providerType,
+		plugin,
+		"github.com/" + namespace + "/" + plugin,
+		"pkg:golang/github.com/" + namespace + "/" + plugin,
+		"pkg:github/" + namespace + "/" + plugin,
+	}
+	for i := range keys {
+		keys[i] = strings.ToLower(keys[i])
+	}
+	return keys
+}
+
+// purlBase strips the version, qualifiers and subpath from a package URL
+func purlBase(purl string) string {
+	if idx := strings.IndexAny(purl, "?#"); idx != -1 {
+		purl = purl[:idx]
+	}
+	if idx := strings.LastIndex(purl, "@"); idx != -1 {
+		purl = purl[:idx]
+	}
+	return strings.ToLower(purl)
+}
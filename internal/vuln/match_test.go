@@ -0,0 +1,74 @@
+package vuln
+
+import (
+	"slices"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	writeAdvisories(t, dir)
+	db, err := Load(dir)
+	if err != nil {
+		t.Fatalf("Load() = %v, want nil", err)
+	}
+
+	s := &sbom.SBOM{
+		Modules: []sbom.ModuleInfo{
+			{Name: "logs", Source: "terraform-aws-modules/s3-bucket/aws", Version: "3.0.1", PURL: "pkg:terraform/terraform-aws-modules/s3-bucket@3.0.1?provider=aws", Location: "Module call at main.tf:1", Filename: "main.tf"},
+			{Name: "fixed", Source: "terraform-aws-modules/s3-bucket/aws", Version: "3.2.0", Location: "Module call at main.tf:6", Filename: "main.tf"},
+			{Name: "unpinned", Source: "terraform-aws-modules/s3-bucket/aws", Version: "~> 3.0", Location: "Module call at main.tf:11", Filename: "main.tf"},
+			{Name: "network", Source: "github.com/my-org/network?ref=v1.4.1", Location: "Module call at main.tf:16", Filename: "main.tf"},
+		},
+		Providers: []sbom.ProviderInfo{
+			{Name: "xyz", Source: "example/xyz", Version: "2.3.1", Directory: "/project", LockFile: "/project/.terraform.lock.hcl", Location: "Provider requirement at versions.tf:3", Filename: "versions.tf"},
+			// The same lock file selection seen from a called module is not reported again
+			{Name: "xyz", Source: "example/xyz", Version: "2.3.1", Directory: "/project/modules/app", LockFile: "/project/.terraform.lock.hcl"},
+			{Name: "xyz", Source: "example/xyz", Version: "2.3.2", Directory: "/other", LockFile: "/other/.terraform.lock.hcl"},
+			{Name: "xyz", Source: "example/xyz", VersionConstraints: []string{">= 2.0"}, Directory: "/unlocked"},
+		},
+	}
+
+	findings := Match(db, s)
+
+	expected := []struct {
+		id        string
+		kind      string
+		component string
+		version   string
+	}{
+		{"TF-2024-0002", ComponentModule, "logs", "3.0.1"},
+		{"TF-2024-0003", ComponentModule, "network", "1.4.1"},
+		{"GHSA-prov-0001", ComponentProvider, "registry.terraform.io/example/xyz", "2.3.1"},
+	}
+	if len(findings) != len(expected) {
+		t.Fatalf("Match() = %+v, want %d findings", findings, len(expected))
+	}
+	for i, want := range expected {
+		got := findings[i]
+		if got.ID != want.id || got.ComponentType != want.kind || got.Component != want.component || got.Version != want.version || got.Status != sbom.VulnerabilityAffected {
+			t.Errorf("findings[%d] = %s %s %s %s, want %+v", i, got.ID, got.ComponentType, got.Component, got.Version, want)
+		}
+	}
+
+	module := findings[0]
+	if module.PURL != "pkg:terraform/terraform-aws-modules/s3-bucket@3.0.1?provider=aws" || module.Filename != "main.tf" || module.Location != "Module call at main.tf:1" {
+		t.Errorf("findings[0] = %+v, want the module's purl and location", module)
+	}
+	if module.Severity != "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N" {
+		t.Errorf("findings[0].Severity = %q, want the CVSS vector", module.Severity)
+	}
+
+	provider := findings[2]
+	if provider.Severity != "HIGH" || !slices.Equal(provider.FixedVersions, []string{"2.3.2"}) {
+		t.Errorf("findings[2] = %+v, want HIGH severity fixed in 2.3.2", provider)
+	}
+	if !slices.Equal(provider.Aliases, []string{"CVE-2024-0001"}) || !slices.Equal(provider.References, []string{"https://example.com/GHSA-prov-0001"}) {
+		t.Errorf("findings[2] = %+v, want the advisory's aliases and references", provider)
+	}
+	if provider.Filename != "versions.tf" {
+		t.Errorf("findings[2].Filename = %q, want versions.tf", provider.Filename)
+	}
+}
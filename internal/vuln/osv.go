@@ -0,0 +1,186 @@
+package vuln
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Advisory is the subset of an OSV advisory used for matching, as described by
+// https://ossf.github.io/osv-schema/
+type Advisory struct {
+	ID               string          `json:"id"`
+	Aliases          []string        `json:"aliases,omitempty"`
+	Summary          string          `json:"summary,omitempty"`
+	Details          string          `json:"details,omitempty"`
+	Withdrawn        string          `json:"withdrawn,omitempty"`
+	Severity         []Severity      `json:"severity,omitempty"`
+	Affected         []Affected      `json:"affected"`
+	References       []Reference     `json:"references,omitempty"`
+	DatabaseSpecific json.RawMessage `json:"database_specific,omitempty"`
+}
+
+// Severity is a severity score such as a CVSS vector
+type Severity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+// Affected lists the affected versions of one package
+type Affected struct {
+	Package  Package  `json:"package"`
+	Ranges   []Range  `json:"ranges,omitempty"`
+	Versions []string `json:"versions,omitempty"`
+}
+
+// Package identifies an affected package by ecosystem and name, or by package URL
+type Package struct {
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	PURL      string `json:"purl,omitempty"`
+}
+
+// Range is a range of affected versions described by ordered events
+type Range struct {
+	Type   string  `json:"type"`
+	Events []Event `json:"events"`
+}
+
+// Event starts or ends a range of affected versions. Exactly one field is set.
+type Event struct {
+	Introduced   string `json:"introduced,omitempty"`
+	Fixed        string `json:"fixed,omitempty"`
+	LastAffected string `json:"last_affected,omitempty"`
+	Limit        string `json:"limit,omitempty"`
+}
+
+// Reference is a link to more information about an advisory
+type Reference struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+// Database is a set of advisories indexed by affected package
+type Database struct {
+	advisories []*Advisory
+	byPackage  map[string][]*Advisory
+}
+
+// Load reads OSV advisories from a directory of JSON files, searched recursively, or
+// from a zip archive such as the per-ecosystem all.zip exports. Withdrawn advisories
+// are skipped.
+func Load(path string) (*Database, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read advisory database: %w", err)
+	}
+
+	db := &Database{byPackage: make(map[string][]*Advisory)}
+	if info.IsDir() {
+		err = db.loadDirectory(path)
+	} else {
+		err = db.loadZip(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Match advisories in a stable order whatever order the files were read in
+	sort.Slice(db.advisories, func(i, j int) bool { return db.advisories[i].ID < db.advisories[j].ID })
+	for _, advisory := range db.advisories {
+		for _, affected := range advisory.Affected {
+			for _, key := range packageKeys(affected.Package) {
+				db.byPackage[key] = append(db.byPackage[key], advisory)
+			}
+		}
+	}
+	return db, nil
+}
+
+// Len returns the number of advisories in the database
+func (db *Database) Len() int {
+	return len(db.advisories)
+}
+
+// loadDirectory reads every JSON file below dir
+func (db *Database) loadDirectory(dir string) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return fmt.Errorf("failed to read advisory database: %w", err)
+		}
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			return nil
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read advisory %s: %w", path, err)
+		}
+		return db.add(path, content)
+	})
+}
+
+// loadZip reads every JSON file in a zip archive
+func (db *Database) loadZip(path string) error {
+	archive, err := zip.OpenReader(path)
+	if err != nil {
+		return fmt.Errorf("failed to open advisory archive %s: %w", path, err)
+	}
+	defer archive.Close()
+
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() || !strings.HasSuffix(file.Name, ".json") {
+			continue
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read advisory %s in %s: %w", file.Name, path, err)
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read advisory %s in %s: %w", file.Name, path, err)
+		}
+		if err := db.add(file.Name, content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add parses one advisory
+func (db *Database) add(name string, content []byte) error {
+	var advisory Advisory
+	if err := json.Unmarshal(content, &advisory); err != nil {
+		return fmt.Errorf("failed to parse advisory %s: %w", name, err)
+	}
+	if advisory.ID == "" {
+		return fmt.Errorf("failed to parse advisory %s: missing id", name)
+	}
+	if advisory.Withdrawn == "" {
+		db.advisories = append(db.advisories, &advisory)
+	}
+	return nil
+}
+
+// severity returns the advisory's severity rating, such as "HIGH" in GitHub
+// advisories, falling back to its first severity score
+func (a *Advisory) severity() string {
+	var specific struct {
+		Severity string `json:"severity"`
+	}
+	if len(a.DatabaseSpecific) > 0 && json.Unmarshal(a.DatabaseSpecific, &specific) == nil && specific.Severity != "" {
+		return specific.Severity
+	}
+	if len(a.Severity) > 0 {
+		return a.Severity[0].Score
+	}
+	return ""
+}
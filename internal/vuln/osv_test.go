@@ -0,0 +1,144 @@
+package vuln
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testAdvisories are OSV advisories for a provider, a registry module and a GitHub module
+var testAdvisories = map[string]string{
+	"GHSA-prov-0001.json": `{
+  "id": "GHSA-prov-0001",
+  "aliases": ["CVE-2024-0001"],
+  "summary": "Credentials logged by terraform-provider-xyz",
+  "affected": [{
+    "package": {"ecosystem": "Go", "name": "github.com/example/terraform-provider-xyz"},
+    "ranges": [{"type": "SEMVER", "events": [{"introduced": "2.0.0"}, {"fixed": "2.3.2"}]}]
+  }],
+  "references": [{"type": "ADVISORY", "url": "https://example.com/GHSA-prov-0001"}],
+  "database_specific": {"severity": "HIGH"}
+}`,
+	"modules/TF-2024-0002.json": `{
+  "id": "TF-2024-0002",
+  "summary": "Public buckets",
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:N/A:N"}],
+  "affected": [{
+    "package": {"ecosystem": "Terraform", "name": "terraform-aws-modules/s3-bucket/aws", "purl": "pkg:terraform/terraform-aws-modules/s3-bucket"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"last_affected": "3.1.0"}]}]
+  }]
+}`,
+	"modules/TF-2024-0003.json": `{
+  "id": "TF-2024-0003",
+  "summary": "Insecure defaults",
+  "affected": [{
+    "package": {"ecosystem": "GitHub Actions", "name": "github.com/my-org/network"},
+    "versions": ["1.4.0", "v1.4.1"]
+  }]
+}`,
+	"withdrawn.json": `{
+  "id": "TF-2024-0004",
+  "withdrawn": "2024-02-01T00:00:00Z",
+  "affected": [{"package": {"name": "terraform-aws-modules/s3-bucket/aws"}, "versions": ["3.0.0"]}]
+}`,
+	"README.md": "not an advisory",
+}
+
+// writeAdvisories writes the test advisories below dir
+func writeAdvisories(t *testing.T, dir string) {
+	t.Helper()
+	for name, content := range testAdvisories {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		writeAdvisories(t, dir)
+
+		db, err := Load(dir)
+		if err != nil {
+			t.Fatalf("Load() = %v, want nil", err)
+		}
+		// The withdrawn advisory is skipped
+		if db.Len() != 3 {
+			t.Errorf("Len() = %v, want 3", db.Len())
+		}
+	})
+
+	t.Run("zip archive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "all.zip")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("failed to create archive: %v", err)
+		}
+		archive := zip.NewWriter(file)
+		for name, content := range testAdvisories {
+			writer, err := archive.Create(name)
+			if err != nil {
+				t.Fatalf("failed to add %s: %v", name, err)
+			}
+			if _, err := writer.Write([]byte(content)); err != nil {
+				t.Fatalf("failed to write %s: %v", name, err)
+			}
+		}
+		if err := archive.Close(); err != nil {
+			t.Fatalf("failed to close archive: %v", err)
+		}
+		file.Close()
+
+		db, err := Load(path)
+		if err != nil {
+			t.Fatalf("Load() = %v, want nil", err)
+		}
+		if db.Len() != 3 {
+			t.Errorf("Len() = %v, want 3", db.Len())
+		}
+	})
+
+	t.Run("invalid advisory", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "bad.json"), []byte(`{"id":`), 0644); err != nil {
+			t.Fatalf("failed to write advisory: %v", err)
+		}
+		_, err := Load(dir)
+		if err == nil || !strings.Contains(err.Error(), "failed to parse advisory") {
+			t.Errorf("Load() = %v, want 'failed to parse advisory'", err)
+		}
+	})
+
+	t.Run("advisory without id", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "empty.json"), []byte(`{"affected": []}`), 0644); err != nil {
+			t.Fatalf("failed to write advisory: %v", err)
+		}
+		if _, err := Load(dir); err == nil {
+			t.Error("Load() = nil, want error for an advisory without id")
+		}
+	})
+
+	t.Run("not an archive", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "advisories.zip")
+		if err := os.WriteFile(path, []byte("not a zip"), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		if _, err := Load(path); err == nil {
+			t.Error("Load() = nil, want error for an invalid archive")
+		}
+	})
+
+	t.Run("missing path", func(t *testing.T) {
+		if _, err := Load(filepath.Join(t.TempDir(), "missing")); err == nil {
+			t.Error("Load() = nil, want error for a missing path")
+		}
+	})
+}
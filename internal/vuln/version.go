@@ -0,0 +1,82 @@
+package vuln
+
+import (
+	"sort"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// affects reports whether version is listed as affected or falls in one of the
+// semantic version ranges of an affected package. Git commit ranges are not evaluated.
+func affects(affected Affected, version string) bool {
+	for _, listed := range affected.Versions {
+		if strings.TrimPrefix(listed, "v") == version {
+			return true
+		}
+	}
+
+	v, err := sbom.ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, r := range affected.Ranges {
+		if (r.Type == rangeSemver || r.Type == rangeEcosystem) && inRange(r.Events, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeEvent is an event with its parsed version
+type rangeEvent struct {
+	event   Event
+	version sbom.Version
+}
+
+// inRange replays the events of a range in version order, as described by the OSV
+// schema: a version is affected after an introduced event at or below it, until a
+// fixed event at or below it or a last_affected event below it. Events whose version
+// cannot be parsed are ignored.
+func inRange(events []Event, v sbom.Version) bool {
+	var parsed []rangeEvent
+	for _, event := range events {
+		value := event.Introduced + event.Fixed + event.LastAffected
+		if value == "" {
+			continue
+		}
+		version, err := sbom.ParseVersion(value)
+		if err != nil {
+			continue
+		}
+		parsed = append(parsed, rangeEvent{event: event, version: version})
+	}
+	sort.SliceStable(parsed, func(i, j int) bool { return parsed[i].version.Compare(parsed[j].version) < 0 })
+
+	affected := false
+	for _, e := range parsed {
+		cmp := e.version.Compare(v)
+		switch {
+		case e.event.Introduced != "" && cmp <= 0:
+			affected = true
+		case e.event.Fixed != "" && cmp <= 0:
+			affected = false
+		case e.event.LastAffected != "" && cmp < 0:
+			affected = false
+		}
+	}
+	return affected
+}
+
+// fixedVersions returns the versions that fix an affected package, in range order
+func fixedVersions(affected Affected) []string {
+	var fixed []string
+	for _, r := range affected.Ranges {
+		for _, event := range r.Events {
+			if event.Fixed != "" {
+				fixed = append(fixed, event.Fixed)
+			}
+		}
+	}
+	return fixed
+}
@@ -0,0 +1,39 @@
+package vuln
+
+import "testing"
+
+func TestAffects(t *testing.T) {
+	semver := func(events ...Event) Affected {
+		return Affected{Ranges: []Range{{Type: rangeSemver, Events: events}}}
+	}
+
+	tests := []struct {
+		name     string
+		affected Affected
+		version  string
+		want     bool
+	}{
+		{"listed version", Affected{Versions: []string{"1.0.0", "1.0.1"}}, "1.0.1", true},
+		{"listed version with prefix", Affected{Versions: []string{"v1.0.1"}}, "1.0.1", true},
+		{"unlisted version", Affected{Versions: []string{"1.0.0"}}, "1.0.2", false},
+		{"introduced", semver(Event{Introduced: "1.2.0"}), "1.2.0", true},
+		{"before introduced", semver(Event{Introduced: "1.2.0"}), "1.1.9", false},
+		{"before fixed", semver(Event{Introduced: "0"}, Event{Fixed: "2.0.0"}), "1.9.9", true},
+		{"fixed", semver(Event{Introduced: "0"}, Event{Fixed: "2.0.0"}), "2.0.0", false},
+		{"last affected", semver(Event{Introduced: "1.0.0"}, Event{LastAffected: "1.5.0"}), "1.5.0", true},
+		{"after last affected", semver(Event{Introduced: "1.0.0"}, Event{LastAffected: "1.5.0"}), "1.5.1", false},
+		{"second range", semver(Event{Introduced: "1.0.0"}, Event{Fixed: "1.1.0"}, Event{Introduced: "2.0.0"}, Event{Fixed: "2.1.0"}), "2.0.5", true},
+		{"between ranges", semver(Event{Introduced: "1.0.0"}, Event{Fixed: "1.1.0"}, Event{Introduced: "2.0.0"}, Event{Fixed: "2.1.0"}), "1.5.0", false},
+		{"unordered events", semver(Event{Fixed: "1.1.0"}, Event{Introduced: "1.0.0"}), "1.0.5", true},
+		{"git ranges are not evaluated", Affected{Ranges: []Range{{Type: "GIT", Events: []Event{{Introduced: "0"}}}}}, "1.0.0", false},
+		{"unparsable version", semver(Event{Introduced: "0"}), "latest", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := affects(tt.affected, tt.version); got != tt.want {
+				t.Errorf("affects(%q) = %v, want %v", tt.version, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package vuln
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// OpenVEX justifications for a not_affected status
+var vexJustifications = []string{
+	"component_not_present",
+	"vulnerable_code_not_present",
+	"vulnerable_code_not_in_execute_path",
+	"vulnerable_code_cannot_be_controlled_by_adversary",
+	"inline_mitigations_already_exist",
+}
+
+// VEXDocument is the subset of an OpenVEX document used to triage findings, as
+// described by https://github.com/openvex/spec
+type VEXDocument struct {
+	Context    string         `json:"@context"`
+	ID         string         `json:"@id"`
+	Statements []VEXStatement `json:"statements"`
+}
+
+// VEXStatement records the status of a vulnerability in a set of products
+type VEXStatement struct {
+	Vulnerability   VEXVulnerability `json:"vulnerability"`
+	Products        []VEXProduct     `json:"products"`
+	Status          string           `json:"status"`
+	Justification   string           `json:"justification,omitempty"`
+	ImpactStatement string           `json:"impact_statement,omitempty"`
+}
+
+// VEXVulnerability names a vulnerability by identifier and aliases
+type VEXVulnerability struct {
+	ID      string   `json:"@id,omitempty"`
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+}
+
+// UnmarshalJSON also accepts the plain string identifiers used before OpenVEX 0.2.0
+func (v *VEXVulnerability) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte(`"`)) {
+		*v = VEXVulnerability{}
+		return json.Unmarshal(data, &v.Name)
+	}
+	type plain VEXVulnerability
+	return json.Unmarshal(data, (*plain)(v))
+}
+
+// VEXProduct identifies a product, or the subcomponents of a product, that a
+// statement applies to
+type VEXProduct struct {
+	ID            string            `json:"@id"`
+	Identifiers   map[string]string `json:"identifiers,omitempty"`
+	Subcomponents []VEXProduct      `json:"subcomponents,omitempty"`
+}
+
+// LoadVEX reads an OpenVEX document and checks that every not_affected statement
+// explains why
+func LoadVEX(path string) (*VEXDocument, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read VEX document %s: %w", path, err)
+	}
+
+	var doc VEXDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse VEX document %s: %w", path, err)
+	}
+
+	for i, statement := range doc.Statements {
+		if statement.Status != sbom.VulnerabilityNotAffected {
+			continue
+		}
+		if statement.Justification == "" && statement.ImpactStatement == "" {
+			return nil, fmt.Errorf("invalid VEX document %s: statement %d marks %s not_affected without a justification or impact_statement", path, i+1, statement.Vulnerability.name())
+		}
+		if statement.Justification != "" && !slices.Contains(vexJustifications, statement.Justification) {
+			return nil, fmt.Errorf("invalid VEX document %s: statement %d has unknown justification %q", path, i+1, statement.Justification)
+		}
+	}
+	return &doc, nil
+}
+
+// Apply updates the status of each finding from the statements that match it.
+// Statements are applied in document order, so a later statement overrides an earlier one.
+func (doc *VEXDocument) Apply(findings []sbom.Vulnerability) {
+	for i := range findings {
+		finding := &findings[i]
+		keys := findingKeys(*finding)
+
+		for _, statement := range doc.Statements {
+			if !statement.Vulnerability.matches(*finding) || !statement.appliesTo(keys, finding.Version) {
+				continue
+			}
+			if statement.Status == sbom.VulnerabilityNotAffected {
+				finding.Status = sbom.VulnerabilityNotAffected
+				finding.Justification = statement.Justification
+				finding.Impact = statement.ImpactStatement
+			} else {
+				finding.Status = sbom.VulnerabilityAffected
+				finding.Justification = ""
+				finding.Impact = ""
+			}
+		}
+	}
+}
+
+// name returns the identifier the vulnerability is known by
+func (v VEXVulnerability) name() string {
+	if v.Name != "" {
+		return v.Name
+	}
+	return v.ID
+}
+
+// matches reports whether the statement's vulnerability is the finding's advisory or
+// one of its aliases
+func (v VEXVulnerability) matches(finding sbom.Vulnerability) bool {
+	ids := append([]string{finding.ID}, finding.Aliases...)
+	for _, name := range append([]string{v.Name, v.ID}, v.Aliases...) {
+		if name != "" && slices.Contains(ids, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// appliesTo reports whether any product, or subcomponent of a product, identifies the
+// component with the given keys. A product identifier without a version applies to
+// every version.
+func (s VEXStatement) appliesTo(keys []string, version string) bool {
+	var match func(products []VEXProduct) bool
+	match = func(products []VEXProduct) bool {
+		for _, product := range products {
+			for _, id := range []string{product.ID, product.Identifiers["purl"]} {
+				if id != "" && productMatches(id, keys, version) {
+					return true
+				}
+			}
+			if match(product.Subcomponents) {
+				return true
+			}
+		}
+		return false
+	}
+	return match(s.Products)
+}
+
+// productMatches reports whether a product identifier names the component
+func productMatches(id string, keys []string, version string) bool {
+	if !slices.Contains(keys, purlBase(id)) {
+		return false
+	}
+
+	// The version is the part after the last "@", before any qualifiers or subpath
+	if idx := strings.IndexAny(id, "?#"); idx != -1 {
+		id = id[:idx]
+	}
+	idx := strings.LastIndex(id, "@")
+	if idx == -1 {
+		return true
+	}
+	return strings.TrimPrefix(id[idx+1:], "v") == version
+}
+
+// findingKeys returns the normalized identifiers of the component a finding affects
+func findingKeys(finding sbom.Vulnerability) []string {
+	var keys []string
+	if finding.PURL != "" {
+		keys = append(keys, purlBase(finding.PURL))
+	}
+	if finding.ComponentType == ComponentProvider {
+		keys = append(keys, providerKeys(sbom.ProviderInfo{Source: finding.Component})...)
+	}
+	return keys
+}
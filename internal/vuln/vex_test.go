@@ -0,0 +1,135 @@
+package vuln
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"rodstewart/terraform-sbom/internal/sbom"
+)
+
+// writeVEX writes an OpenVEX document and returns its path
+func writeVEX(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "triage.openvex.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write VEX document: %v", err)
+	}
+	return path
+}
+
+func TestLoadVEX(t *testing.T) {
+	t.Run("valid document", func(t *testing.T) {
+		doc, err := LoadVEX(writeVEX(t, `{
+  "@context": "https://openvex.dev/ns/v0.2.0",
+  "@id": "https://example.com/vex/1",
+  "statements": [
+    {"vulnerability": {"name": "CVE-2024-1"}, "products": [{"@id": "pkg:terraform/ns/bucket"}], "status": "not_affected", "justification": "component_not_present"},
+    {"vulnerability": "CVE-2024-2", "products": [{"@id": "pkg:terraform/ns/bucket"}], "status": "affected"}
+  ]
+}`))
+		if err != nil {
+			t.Fatalf("LoadVEX() = %v, want nil", err)
+		}
+		if len(doc.Statements) != 2 {
+			t.Fatalf("len(Statements) = %v, want 2", len(doc.Statements))
+		}
+		// Identifiers written as plain strings by OpenVEX 0.0.x are accepted
+		if doc.Statements[1].Vulnerability.Name != "CVE-2024-2" {
+			t.Errorf("Statements[1].Vulnerability = %+v, want CVE-2024-2", doc.Statements[1].Vulnerability)
+		}
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"invalid JSON", `{"statements":`, "failed to parse VEX document"},
+		{"not_affected without reason", `{"statements": [{"vulnerability": {"name": "CVE-2024-1"}, "status": "not_affected"}]}`, "without a justification"},
+		{"unknown justification", `{"statements": [{"vulnerability": {"name": "CVE-2024-1"}, "status": "not_affected", "justification": "trust_me"}]}`, "unknown justification"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadVEX(writeVEX(t, tt.content))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("LoadVEX() = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		if _, err := LoadVEX(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+			t.Error("LoadVEX() = nil, want error for a missing file")
+		}
+	})
+}
+
+func TestApplyVEX(t *testing.T) {
+	doc, err := LoadVEX(writeVEX(t, `{
+  "statements": [
+    {
+      "vulnerability": {"name": "CVE-2024-1"},
+      "products": [{"@id": "pkg:terraform/ns/bucket"}],
+      "status": "not_affected",
+      "justification": "vulnerable_code_not_in_execute_path",
+      "impact_statement": "Buckets are private"
+    },
+    {
+      "vulnerability": {"name": "GHSA-2"},
+      "products": [{"@id": "https://example.com/repo", "subcomponents": [{"@id": "hashicorp/aws@5.1.0"}]}],
+      "status": "not_affected",
+      "impact_statement": "Only read-only credentials are configured"
+    },
+    {
+      "vulnerability": {"name": "GHSA-3"},
+      "products": [{"@id": "registry.terraform.io/hashicorp/aws"}],
+      "status": "not_affected",
+      "justification": "component_not_present"
+    },
+    {
+      "vulnerability": {"name": "GHSA-3"},
+      "products": [{"@id": "registry.terraform.io/hashicorp/aws@5.1.0"}],
+      "status": "affected"
+    }
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("LoadVEX() = %v, want nil", err)
+	}
+
+	findings := []sbom.Vulnerability{
+		// Matched through the advisory's alias, for any version of the module
+		{ID: "TF-1", Aliases: []string{"CVE-2024-1"}, ComponentType: ComponentModule, Component: "logs", PURL: "pkg:terraform/ns/bucket@3.0.1?provider=aws", Version: "3.0.1", Status: sbom.VulnerabilityAffected},
+		// Another module is not covered by the statement
+		{ID: "TF-1", Aliases: []string{"CVE-2024-1"}, ComponentType: ComponentModule, Component: "vpc", PURL: "pkg:terraform/ns/vpc@1.0.0?provider=aws", Version: "1.0.0", Status: sbom.VulnerabilityAffected},
+		// A subcomponent naming the provider without its registry host
+		{ID: "GHSA-2", ComponentType: ComponentProvider, Component: "registry.terraform.io/hashicorp/aws", Version: "5.1.0", Status: sbom.VulnerabilityAffected},
+		// The subcomponent pins another version
+		{ID: "GHSA-2", ComponentType: ComponentProvider, Component: "registry.terraform.io/hashicorp/aws", Version: "5.2.0", Status: sbom.VulnerabilityAffected},
+		// A later statement marks this version affected again
+		{ID: "GHSA-3", ComponentType: ComponentProvider, Component: "registry.terraform.io/hashicorp/aws", Version: "5.1.0", Status: sbom.VulnerabilityAffected},
+		{ID: "GHSA-3", ComponentType: ComponentProvider, Component: "registry.terraform.io/hashicorp/aws", Version: "5.3.0", Status: sbom.VulnerabilityAffected},
+	}
+	doc.Apply(findings)
+
+	expected := []struct {
+		suppressed    bool
+		justification string
+		impact        string
+	}{
+		{true, "vulnerable_code_not_in_execute_path", "Buckets are private"},
+		{false, "", ""},
+		{true, "", "Only read-only credentials are configured"},
+		{false, "", ""},
+		{false, "", ""},
+		{true, "component_not_present", ""},
+	}
+	for i, want := range expected {
+		got := findings[i]
+		if got.Suppressed() != want.suppressed || got.Justification != want.justification || got.Impact != want.impact {
+			t.Errorf("findings[%d] = %s %q %q, want suppressed %v with %q %q", i, got.Status, got.Justification, got.Impact, want.suppressed, want.justification, want.impact)
+		}
+	}
+}